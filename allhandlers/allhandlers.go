@@ -4,62 +4,117 @@ import (
 	"net/http"
 	"strings"
 
+	"form-project/bookmarkhandlers"
+	"form-project/events"
+	"form-project/healthhandlers"
 	"form-project/homehandlers"
+	"form-project/markdown"
+	"form-project/media"
+	"form-project/metrics"
 	"form-project/morehandlers"
+	"form-project/notificationhandlers"
 	"form-project/posthandlers"
+	"form-project/storage"
+	"form-project/utils"
 )
 
 func Allhandlers() {
 	// Statik Dosya Sunumu:
-	http.HandleFunc("/static/", func(w http.ResponseWriter, r *http.Request) {
+	handleFunc("/static/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path[1:]
 		if !strings.HasPrefix(path, "static/") {
-			http.NotFound(w, r)
+			utils.WriteError(w, r, utils.NotFound("File not found"))
 			return
 		}
 		http.ServeFile(w, r, path)
 	})
-	http.HandleFunc("/google/register", homehandlers.HandleGoogleRegister)
-	http.Handle("/uploads/", http.StripPrefix("/uploads/", http.FileServer(http.Dir("./uploads"))))
-	http.HandleFunc("/upload", homehandlers.UploadHandler)
+	handleFunc("/highlight.css", markdown.StylesheetHandler)
+	handleFunc("/google/register", homehandlers.HandleGoogleRegister)
+	handle("/uploads/", storage.Handler(media.Store))
+	handleUpload("/upload", homehandlers.UploadHandler, media.MaxFileSize)
 
 	// Google Oturum İşlemleri:
-	http.HandleFunc("/google/login", homehandlers.HandleGoogleLogin)
-	http.HandleFunc("/google/callback", homehandlers.HandleGoogleCallback)
+	handleFunc("/google/login", homehandlers.HandleGoogleLogin)
+	handleFunc("/google/callback", homehandlers.HandleGoogleCallback)
 
 	// GitHub Oturum İşlemleri:
-	http.HandleFunc("/github/login", homehandlers.HandleGitHubLogin)
-	http.HandleFunc("/github/callback", homehandlers.HandleGitHubCallback)
+	handleFunc("/github/login", homehandlers.HandleGitHubLogin)
+	handleFunc("/github/callback", homehandlers.HandleGitHubCallback)
 	// Facebook Oturum İşlemleri:
-	http.HandleFunc("/facebook/login", homehandlers.HandleFacebookLogin)
-	http.HandleFunc("/facebook/callback", homehandlers.HandleFacebookCallback)
+	handleFunc("/facebook/login", homehandlers.HandleFacebookLogin)
+	handleFunc("/facebook/callback", homehandlers.HandleFacebookCallback)
 
 	// Diğer İşleyiciler:
-	http.HandleFunc("/", homehandlers.HomeHandler)
-	http.HandleFunc("/register", homehandlers.RegisterHandler)
-	http.HandleFunc("/login", homehandlers.LoginHandler)
-	http.HandleFunc("/logout", homehandlers.LogoutHandler)
-	http.HandleFunc("/sifreunut", homehandlers.SifreUnutHandler)
-	http.HandleFunc("/admin", homehandlers.AdminHandler)
+	handleFunc("/", homehandlers.HomeHandler)
+	handleFunc("/register", homehandlers.RegisterHandler)
+	handleFunc("/login", homehandlers.LoginHandler)
+	handleFunc("/logout", homehandlers.LogoutHandler)
+	handleFunc("/sifreunut", homehandlers.SifreUnutHandler)
+	handleFunc("/admin", homehandlers.AdminHandler)
+	handleFunc("/admin/quotas", homehandlers.UpdateRoleQuotaHandler)
 
 	// Gönderi İşlemleri:
-	http.HandleFunc("/createPost", posthandlers.CreatePostHandler)
-	http.HandleFunc("/createComment", posthandlers.CreateCommentHandler)
-	http.HandleFunc("/deletePost", posthandlers.DeletePostHandler)
-	http.HandleFunc("/deleteComment", posthandlers.DeleteCommentHandler)
-	http.HandleFunc("/vote", posthandlers.VoteHandler)
-	http.HandleFunc("/viewPost", posthandlers.ViewPostHandler)
-	http.HandleFunc("/reportPost/{id}", posthandlers.ReportPostHandler)
+	handleUpload("/createPost", posthandlers.CreatePostHandler, media.MaxRequestSize)
+	handleUpload("/createComment", posthandlers.CreateCommentHandler, media.MaxRequestSize)
+	handleFunc("/deletePost", posthandlers.DeletePostHandler)
+	handleFunc("/deleteComment", posthandlers.DeleteCommentHandler)
+	handleFunc("/vote", posthandlers.VoteHandler)
+	handleFunc("/preview", posthandlers.PreviewHandler)
+	handleFunc("/viewPost", posthandlers.ViewPostHandler)
+	handleFunc("/reportPost/{id}", posthandlers.ReportPostHandler)
+	handleFunc("/comments/{id}", posthandlers.CommentHandler)
+	handleFunc("/posts/follow/{id}", posthandlers.FollowPostHandler)
+	handleFunc("/posts/question/{id}", posthandlers.MarkQuestionHandler)
+	handleFunc("/posts/accept/{id}", posthandlers.AcceptAnswerHandler)
 
 	// Profil İşlemleri:
-	http.HandleFunc("/myprofil", morehandlers.MyProfileHandler)
+	handleFunc("/myprofil", morehandlers.MyProfileHandler)
+	handleUpload("/profile/edit", morehandlers.EditProfileHandler, func() int64 { return media.MaxFileSize() + 1<<20 })
+	handleFunc("/u/{username}", morehandlers.PublicProfileHandler)
+	handleFunc("/u/{username}/block", morehandlers.BlockUserHandler)
+	handleFunc("/u/{username}/follow", morehandlers.FollowUserHandler)
+
+	// Bildirimler:
+	handleFunc("/notifications", notificationhandlers.NotificationsHandler)
+	handleFunc("/notifications/read", notificationhandlers.MarkReadHandler)
+	handleFunc("/notifications/preferences", notificationhandlers.PreferencesHandler)
+	handleFunc("/email/unsubscribe", notificationhandlers.UnsubscribeHandler)
+
+	// Yer imleri (JSON API):
+	handleFunc("/api/bookmarks", bookmarkhandlers.BookmarksHandler)
+	handleFunc("/api/bookmarks/{id}", bookmarkhandlers.BookmarkHandler)
+	handleFunc("/api/bookmark-folders", bookmarkhandlers.FoldersHandler)
+	handleFunc("/api/bookmark-folders/{id}", bookmarkhandlers.FolderHandler)
 
 	// Kullanıcı İşlemleri:
-	http.HandleFunc("/users/edit/", morehandlers.EditUserHandler)     // Kullanıcı düzenleme işlemi için işleyici
-	http.HandleFunc("/users/update/", homehandlers.UpdateUserHandler) // Kullanıcı güncelleme işlemi için işleyici
-	http.HandleFunc("/users/delete/", homehandlers.DeleteUserHandler)
-	http.HandleFunc("/posts/delete/", posthandlers.DeletePostHandler)
+	handleFunc("/users/edit/", morehandlers.EditUserHandler)     // Kullanıcı düzenleme işlemi için işleyici
+	handleFunc("/users/update/", homehandlers.UpdateUserHandler) // Kullanıcı güncelleme işlemi için işleyici
+	handleFunc("/users/delete/", homehandlers.DeleteUserHandler)
+	handleFunc("/posts/delete/", posthandlers.DeletePostHandler)
+
+	handleFunc("/categories/add", homehandlers.AddCategoryHandler)
+	handleFunc("/categories/delete/{id}", homehandlers.DeleteCategoryHandler)
+	handleFunc("/categories/follow", homehandlers.FollowCategoryHandler)
+
+	// Canlı güncellemeler: uzun süre açık kalan akışlar istek süresi metriklerine katılmaz
+	http.HandleFunc("/events", events.Handler)
+
+	// İzleme:
+	http.Handle("/metrics", metrics.Handler())
+	http.HandleFunc("/healthz", healthhandlers.HealthzHandler)
+	http.HandleFunc("/readyz", healthhandlers.ReadyzHandler)
+}
+
+// İşleyiciyi CSRF kontrolü ve kayıtlı rota deseniyle etiketlenmiş metriklerle sararak kaydeder.
+func handle(pattern string, handler http.Handler) {
+	http.Handle(pattern, metrics.InstrumentHandler(pattern, csrfProtect(handler, maxFormSize)))
+}
+
+func handleFunc(pattern string, handler http.HandlerFunc) {
+	handle(pattern, handler)
+}
 
-	http.HandleFunc("/categories/add", homehandlers.AddCategoryHandler)
-	http.HandleFunc("/categories/delete/{id}", homehandlers.DeleteCategoryHandler)
+// Dosya yüklenen rotaları kaydeder; istek gövdesi CSRF kontrolünden önce limit ile sınırlanır.
+func handleUpload(pattern string, handler http.HandlerFunc, limit func() int64) {
+	http.Handle(pattern, metrics.InstrumentHandler(pattern, csrfProtect(handler, limit)))
 }
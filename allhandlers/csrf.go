@@ -0,0 +1,35 @@
+package allhandlers
+
+import (
+	"errors"
+	"net/http"
+
+	"form-project/security"
+	"form-project/utils"
+)
+
+// Dosya yüklenmeyen rotalarda istek gövdesinin en büyük boyutu
+func maxFormSize() int64 { return 1 << 20 }
+
+// Durum değiştiren isteklerde formdaki veya başlıktaki CSRF belirtecini doğrular. Başlık yoksa
+// belirteç için form ayrıştırılacağından gövde önce limit ile sınırlanır; işleyicilerin kendi
+// sınırları form burada ayrıştırıldıktan sonra etkisizdir.
+func csrfProtect(next http.Handler, limit func() int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, limit())
+		}
+		if r.Header.Get(security.CSRFHeaderName) == "" && !security.SafeMethod(r.Method) {
+			var tooLarge *http.MaxBytesError
+			if err := r.ParseMultipartForm(32 << 20); errors.As(err, &tooLarge) {
+				utils.WriteError(w, r, utils.NewError(http.StatusRequestEntityTooLarge, "The request body is too large", err))
+				return
+			}
+		}
+		if !security.ValidCSRF(r) {
+			utils.WriteError(w, r, utils.Forbidden("Invalid or missing CSRF token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,221 @@
+package badges // Katılımı ödüllendiren rozetler ve gönderi, yorum, oy ve kabul edilen cevap olaylarıyla çalışan değerlendirici
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"form-project/datahandlers"
+)
+
+// Event, rozetlerin yeniden değerlendirilmesini tetikleyen bir kullanıcı olayıdır.
+type Event string
+
+const (
+	PostCreated    Event = "post_created"    // Kullanıcı gönderi paylaştı
+	CommentCreated Event = "comment_created" // Kullanıcı yorum yaptı
+	Voted          Event = "voted"           // Kullanıcı oy verdi
+	VoteReceived   Event = "vote_received"   // Kullanıcının içeriği oylandı
+	AnswerAccepted Event = "answer_accepted" // Kullanıcının cevabı kabul edildi
+)
+
+// Herhangi bir etkinlikte değerlendirilen rozetler için
+var anyEvent = []Event{PostCreated, CommentCreated, Voted, VoteReceived, AnswerAccepted}
+
+// Badge, bir rozet tanımıdır. earned rozetin kazanılıp kazanılmadığını ve biliniyorsa
+// ne zaman kazanıldığını döndürür; tarih bilinmiyorsa sıfırdır.
+type Badge struct {
+	ID          string
+	Name        string
+	Description string
+	Icon        string
+	Events      []Event // Rozetin değerlendirildiği olaylar
+	earned      func(userID int, now time.Time) (bool, time.Time, error)
+}
+
+// Rozetler için gereken beğeni ve kabul edilmiş cevap sayıları
+const (
+	LikesForWellLiked       = 100
+	AnswersForProblemSolver = 10
+)
+
+// All, tüm rozetlerdir; profillerde bu sırayla gösterilir.
+var All = []Badge{
+	{
+		ID: "first_post", Name: "First Post", Icon: "✍️",
+		Description: "Published a first post",
+		Events:      []Event{PostCreated},
+		earned: func(userID int, now time.Time) (bool, time.Time, error) {
+			at, err := datahandlers.FirstPostAt(userID)
+			return at.Valid, at.Time, err
+		},
+	},
+	{
+		ID: "well_liked", Name: "Well Liked", Icon: "❤️",
+		Description: fmt.Sprintf("Received %d likes on posts and comments", LikesForWellLiked),
+		Events:      []Event{VoteReceived},
+		earned: func(userID int, now time.Time) (bool, time.Time, error) {
+			likes, err := datahandlers.LikesReceived(userID)
+			return likes >= LikesForWellLiked, time.Time{}, err
+		},
+	},
+	{
+		ID: "problem_solver", Name: "Problem Solver", Icon: "✅",
+		Description: fmt.Sprintf("Had %d answers accepted", AnswersForProblemSolver),
+		Events:      []Event{AnswerAccepted},
+		earned: func(userID int, now time.Time) (bool, time.Time, error) {
+			answers, err := datahandlers.AcceptedAnswers(userID)
+			return answers >= AnswersForProblemSolver, time.Time{}, err
+		},
+	},
+	{
+		ID: "veteran", Name: "Veteran", Icon: "🎖️",
+		Description: "Member for one year",
+		Events:      anyEvent,
+		earned: func(userID int, now time.Time) (bool, time.Time, error) {
+			since, err := datahandlers.MemberSince(userID)
+			if err != nil || !since.Valid {
+				return false, time.Time{}, err
+			}
+			anniversary := since.Time.AddDate(1, 0, 0)
+			return !anniversary.After(now), anniversary, nil
+		},
+	},
+}
+
+// Lookup, ID'si verilen rozeti döndürür.
+func Lookup(id string) (Badge, bool) {
+	for _, b := range All {
+		if b.ID == id {
+			return b, true
+		}
+	}
+	return Badge{}, false
+}
+
+// Awarded, kullanıcıya verilmiş bir rozettir.
+type Awarded struct {
+	Badge
+	AwardedAt time.Time
+}
+
+// ForUser, kullanıcının rozetlerini verilme sırasıyla döndürür. Artık tanımlı olmayan rozetler atlanır.
+func ForUser(userID int) ([]Awarded, error) {
+	held, err := datahandlers.UserBadges(userID)
+	if err != nil {
+		return nil, err
+	}
+	awarded := make([]Awarded, 0, len(held))
+	for _, h := range held {
+		if b, ok := Lookup(h.Badge); ok {
+			awarded = append(awarded, Awarded{Badge: b, AwardedAt: h.AwardedAt})
+		}
+	}
+	return awarded, nil
+}
+
+// Evaluate, olayla ilgili rozetlerden kullanıcının henüz almadıklarını değerlendirir ve
+// kazanılanları verir. Event boşsa tüm rozetler değerlendirilir. Yeni verilen rozetler döner.
+func Evaluate(userID int, event Event, now time.Time) ([]Badge, error) {
+	return evaluate(userID, event, now, false)
+}
+
+func evaluate(userID int, event Event, now time.Time, dryRun bool) ([]Badge, error) {
+	if userID == 0 {
+		return nil, nil
+	}
+	held, err := datahandlers.UserBadges(userID)
+	if err != nil {
+		return nil, err
+	}
+	has := make(map[string]bool, len(held))
+	for _, h := range held {
+		has[h.Badge] = true
+	}
+
+	var awarded []Badge
+	for _, b := range All {
+		if has[b.ID] || (event != "" && !triggers(b, event)) {
+			continue
+		}
+		ok, at, err := b.earned(userID, now)
+		if err != nil {
+			return awarded, fmt.Errorf("error evaluating badge %s for user %d: %v", b.ID, userID, err)
+		}
+		if !ok {
+			continue
+		}
+		if at.IsZero() || at.After(now) {
+			at = now
+		}
+		if !dryRun {
+			if ok, err = datahandlers.AwardBadge(userID, b.ID, at); err != nil {
+				return awarded, fmt.Errorf("error awarding badge %s to user %d: %v", b.ID, userID, err)
+			}
+		}
+		if ok {
+			awarded = append(awarded, b)
+		}
+	}
+	return awarded, nil
+}
+
+func triggers(b Badge, event Event) bool {
+	for _, e := range b.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Trigger, Evaluate'i şimdiki zamanla çalıştırır ve hataları günlüğe yazar; işleyicilerden çağrılır.
+func Trigger(userID int, event Event) {
+	awarded, err := Evaluate(userID, event, time.Now())
+	if err != nil {
+		log.Printf("badges: %v", err)
+	}
+	for _, b := range awarded {
+		log.Printf("badges: awarded %s to user %d", b.ID, userID)
+	}
+}
+
+// VoteChanged, oy veren kullanıcının ve oylanan içeriğin yazarının rozetlerini değerlendirir.
+func VoteChanged(voterID int, postID, commentID int64) {
+	Trigger(voterID, Voted)
+	authorID, err := datahandlers.VoteTargetAuthor(postID, commentID)
+	if err != nil {
+		log.Printf("badges: %v", err)
+		return
+	}
+	if authorID != voterID {
+		Trigger(authorID, VoteReceived)
+	}
+}
+
+// BackfillReport, Backfill'in özetidir.
+type BackfillReport struct {
+	Users   int            // Değerlendirilen kullanıcılar
+	Awarded map[string]int // Rozet başına verilen (dry-run'da verilecek) rozet sayısı
+}
+
+// Backfill, mevcut forum geçmişinden tüm kullanıcıların tüm rozetlerini hesaplar.
+// Tarihi bilinen rozetler (ilk gönderi, üyelik yıldönümü) o tarihle verilir.
+func Backfill(now time.Time, dryRun bool) (BackfillReport, error) {
+	report := BackfillReport{Awarded: make(map[string]int)}
+	ids, err := datahandlers.UserIDs()
+	if err != nil {
+		return report, err
+	}
+	for _, id := range ids {
+		awarded, err := evaluate(id, "", now, dryRun)
+		if err != nil {
+			return report, err
+		}
+		report.Users++
+		for _, b := range awarded {
+			report.Awarded[b.ID]++
+		}
+	}
+	return report, nil
+}
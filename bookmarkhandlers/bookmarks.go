@@ -0,0 +1,395 @@
+package bookmarkhandlers // Yer imleri ve klasörleri için JSON API işleyicileri
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"form-project/datahandlers"
+	"form-project/utils"
+)
+
+// bookmarkInput, yer imi oluşturma ve güncelleme isteğidir. Alanlar form olarak ya da JSON
+// gövdede gönderilebilir; güncellemede verilmeyen alanlar değişmez (folder_id 0: klasörsüz).
+type bookmarkInput struct {
+	PostID    int64   `json:"post_id"`
+	CommentID int64   `json:"comment_id"`
+	FolderID  *int64  `json:"folder_id"`
+	Note      *string `json:"note"`
+}
+
+// folderInput, klasör oluşturma ve yeniden adlandırma isteğidir.
+type folderInput struct {
+	Name string `json:"name"`
+}
+
+// BookmarksHandler, /api/bookmarks adresinde oturumdaki kullanıcının yer imlerini listeler
+// (GET, isteğe bağlı ?folder=<id>) ya da gönderiyi veya yorumu kaydeder (POST; kayıt yeniyse 201,
+// zaten kayıtlıysa verilen alanlar güncellenir ve 200 döner).
+func BookmarksHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		var folderID int64
+		if v := r.URL.Query().Get("folder"); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				utils.WriteError(w, r, utils.BadRequest("Invalid folder ID", err))
+				return
+			}
+			folderID = id
+		}
+		bookmarks, err := datahandlers.UserBookmarks(userID, folderID)
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		folders, err := datahandlers.BookmarkFolders(userID)
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		if bookmarks == nil {
+			bookmarks = []datahandlers.Bookmark{}
+		}
+		if folders == nil {
+			folders = []datahandlers.BookmarkFolder{}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"bookmarks": bookmarks, "folders": folders})
+	case http.MethodPost:
+		var in bookmarkInput
+		if err := decodeBookmark(r, &in); err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+		if in.PostID <= 0 {
+			utils.WriteError(w, r, utils.BadRequest("post_id is required", nil))
+			return
+		}
+		// İçerik zaten kayıtlıysa yalnızca verilen alanlar güncellenir
+		saved, err := datahandlers.PostBookmarks(userID, in.PostID)
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		status := http.StatusCreated
+		var folderID int64
+		var note string
+		if id, ok := saved[in.CommentID]; ok {
+			current, err := datahandlers.UserBookmark(userID, id)
+			if err == nil {
+				status = http.StatusOK
+				folderID, note = current.FolderID, current.Note
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				utils.WriteError(w, r, utils.Internal(err))
+				return
+			}
+		}
+		if in.FolderID != nil {
+			folderID = *in.FolderID
+		}
+		if in.Note != nil {
+			note = *in.Note
+		}
+		id, err := datahandlers.SaveBookmark(userID, in.PostID, in.CommentID, folderID, note)
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteError(w, r, utils.NotFound("Post, comment or folder not found"))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		writeBookmark(w, r, userID, id, status)
+	default:
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+	}
+}
+
+// BookmarkHandler, /api/bookmarks/{id} adresinde tek bir yer imini döndürür (GET), klasörünü
+// ve notunu değiştirir (PATCH) ya da siler (DELETE).
+func BookmarkHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequest("Invalid bookmark ID", err))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeBookmark(w, r, userID, id, http.StatusOK)
+	case http.MethodPatch:
+		var in bookmarkInput
+		if err := decodeBookmark(r, &in); err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+		current, err := datahandlers.UserBookmark(userID, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteError(w, r, utils.NotFound("Bookmark not found"))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		folderID, note := current.FolderID, current.Note
+		if in.FolderID != nil {
+			folderID = *in.FolderID
+		}
+		if in.Note != nil {
+			note = *in.Note
+		}
+		err = datahandlers.UpdateBookmark(userID, id, folderID, note)
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteError(w, r, utils.NotFound("Bookmark or folder not found"))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		writeBookmark(w, r, userID, id, http.StatusOK)
+	case http.MethodDelete:
+		err := datahandlers.DeleteBookmark(userID, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteError(w, r, utils.NotFound("Bookmark not found"))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+	}
+}
+
+// FoldersHandler, /api/bookmark-folders adresinde klasörleri listeler (GET) ya da yeni bir
+// klasör oluşturur (POST, "name").
+func FoldersHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		folders, err := datahandlers.BookmarkFolders(userID)
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		if folders == nil {
+			folders = []datahandlers.BookmarkFolder{}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"folders": folders})
+	case http.MethodPost:
+		name, err := decodeFolderName(r)
+		if err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+		id, err := datahandlers.CreateBookmarkFolder(userID, name)
+		if errors.Is(err, datahandlers.ErrBookmarkFolderExists) {
+			utils.WriteError(w, r, utils.NewError(http.StatusConflict, "A folder with this name already exists", nil))
+			return
+		}
+		if errors.Is(err, datahandlers.ErrTooManyBookmarkFolders) {
+			utils.WriteError(w, r, utils.NewError(http.StatusConflict,
+				"You can have at most "+strconv.Itoa(datahandlers.MaxBookmarkFolders)+" folders", nil))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"folder": datahandlers.BookmarkFolder{ID: id, Name: name}})
+	default:
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+	}
+}
+
+// FolderHandler, /api/bookmark-folders/{id} adresinde klasörü yeniden adlandırır (PATCH, "name")
+// ya da siler (DELETE); silinen klasördeki yer imleri klasörsüz kalır.
+func FolderHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequest("Invalid folder ID", err))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPatch:
+		name, err := decodeFolderName(r)
+		if err != nil {
+			utils.WriteError(w, r, err)
+			return
+		}
+		err = datahandlers.RenameBookmarkFolder(userID, id, name)
+		if errors.Is(err, datahandlers.ErrBookmarkFolderExists) {
+			utils.WriteError(w, r, utils.NewError(http.StatusConflict, "A folder with this name already exists", nil))
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteError(w, r, utils.NotFound("Folder not found"))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		folders, err := datahandlers.BookmarkFolders(userID)
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		for _, f := range folders {
+			if f.ID == id {
+				writeJSON(w, http.StatusOK, map[string]interface{}{"folder": f})
+				return
+			}
+		}
+		utils.WriteError(w, r, utils.NotFound("Folder not found"))
+	case http.MethodDelete:
+		err := datahandlers.DeleteBookmarkFolder(userID, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteError(w, r, utils.NotFound("Folder not found"))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+	}
+}
+
+// Oturumdaki kullanıcının ID'sini döndürür; oturum yoksa 401 yazar.
+func requireUser(w http.ResponseWriter, r *http.Request) (int, bool) {
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		utils.WriteError(w, r, utils.Unauthorized("You need to log in to manage bookmarks"))
+		return 0, false
+	}
+	return session.UserID, true
+}
+
+// İsteğin gövdesini JSON ise JSON, değilse form olarak okur.
+func decodeBookmark(r *http.Request, in *bookmarkInput) error {
+	if isJSON(r) {
+		if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 16<<10)).Decode(in); err != nil {
+			return utils.BadRequest("Invalid JSON body", err)
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return utils.BadRequest("Invalid form", err)
+		}
+		var err error
+		if in.PostID, err = formID(r, "post_id"); err != nil {
+			return err
+		}
+		if in.CommentID, err = formID(r, "comment_id"); err != nil {
+			return err
+		}
+		if _, ok := r.PostForm["folder_id"]; ok {
+			folderID, err := formID(r, "folder_id")
+			if err != nil {
+				return err
+			}
+			in.FolderID = &folderID
+		}
+		if _, ok := r.PostForm["note"]; ok {
+			note := r.PostForm.Get("note")
+			in.Note = &note
+		}
+	}
+
+	if in.Note != nil {
+		note := strings.TrimSpace(*in.Note)
+		if utf8.RuneCountInString(note) > datahandlers.MaxBookmarkNoteLength {
+			return utils.BadRequest("Notes can be at most "+strconv.Itoa(datahandlers.MaxBookmarkNoteLength)+" characters", nil)
+		}
+		in.Note = &note
+	}
+	if in.PostID < 0 || in.CommentID < 0 || (in.FolderID != nil && *in.FolderID < 0) {
+		return utils.BadRequest("Invalid ID", nil)
+	}
+	return nil
+}
+
+// Klasör adını okur ve doğrular.
+func decodeFolderName(r *http.Request) (string, error) {
+	var in folderInput
+	if isJSON(r) {
+		if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 4<<10)).Decode(&in); err != nil {
+			return "", utils.BadRequest("Invalid JSON body", err)
+		}
+	} else {
+		in.Name = r.PostFormValue("name")
+	}
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return "", utils.BadRequest("Folder name is required", nil)
+	}
+	if utf8.RuneCountInString(name) > datahandlers.MaxBookmarkFolderLength {
+		return "", utils.BadRequest("Folder names can be at most "+strconv.Itoa(datahandlers.MaxBookmarkFolderLength)+" characters", nil)
+	}
+	return name, nil
+}
+
+func isJSON(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+}
+
+// Boş form alanını 0 olarak okur.
+func formID(r *http.Request, key string) (int64, error) {
+	v := r.PostForm.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, utils.BadRequest("Invalid "+key, err)
+	}
+	return id, nil
+}
+
+// Yer imini JSON olarak yazar.
+func writeBookmark(w http.ResponseWriter, r *http.Request, userID int, id int64, status int) {
+	bookmark, err := datahandlers.UserBookmark(userID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.WriteError(w, r, utils.NotFound("Bookmark not found"))
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	writeJSON(w, status, map[string]interface{}{"bookmark": bookmark})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
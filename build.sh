@@ -5,3 +5,6 @@ docker build -t forum .
 
 # Run the Docker container
 docker run -d -p 8065:8065 --name forum-container forum
+
+# Show the container health status (starting, healthy, unhealthy)
+docker inspect --format '{{.State.Health.Status}}' forum-container
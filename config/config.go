@@ -0,0 +1,189 @@
+package config // config.json dosyasından okunan uygulama yapılandırması
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Varsayılan yapılandırma dosyası
+const DefaultPath = "config.json"
+
+type Config struct {
+	GoogleClientID       string `json:"google_client_id"`
+	GoogleClientSecret   string `json:"google_client_secret"`
+	GitHubClientID       string `json:"github_client_id"`
+	GitHubClientSecret   string `json:"github_client_secret"`
+	FacebookClientID     string `json:"facebook_client_id"`
+	FacebookClientSecret string `json:"facebook_client_secret"`
+
+	Storage Storage `json:"storage"`
+	Uploads Uploads `json:"uploads"`
+	Mail    Mail    `json:"mail"`
+
+	Reputation Reputation `json:"reputation"`
+}
+
+// Mail, bildirim e-postalarının gönderim ayarlarıdır. Driver boşsa e-posta gönderilmez.
+type Mail struct {
+	Driver  string `json:"driver"`   // "smtp", "file" (yerel test için .eml dosyaları) veya boş
+	From    string `json:"from"`     // Gönderen adresi, ör. "Software News <forum@example.com>"
+	BaseURL string `json:"base_url"` // E-postalardaki bağlantıların kökü, ör. https://forum.example.com
+	Dir     string `json:"dir"`      // file: e-postaların yazılacağı dizin, varsayılan ./outbox
+	SMTP    SMTP   `json:"smtp"`
+}
+
+// SMTP, e-posta sunucusunun bağlantı bilgileridir. Sunucu destekliyorsa STARTTLS kullanılır.
+type SMTP struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"` // Varsayılan 587
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Uploads, yükleme sınırlarıdır. Boyutlar MB cinsindendir; 0 kota sınırsız demektir.
+// Admin, rol ve kullanıcı kotalarını panelden ayrıca ezebilir.
+type Uploads struct {
+	MaxFileSizeMB map[string]int64 `json:"max_file_size_mb"` // Dosya türüne göre: image, pdf, text
+	QuotaMB       map[string]int64 `json:"quota_mb"`         // Role göre kullanıcı başına toplam: user, moderator, admin
+}
+
+// Yapılandırmada verilmeyen sınırlar için varsayılanlar
+var (
+	DefaultMaxFileSizeMB = map[string]int64{"image": 20, "pdf": 10, "text": 1}
+	DefaultQuotaMB       = map[string]int64{"user": 100, "moderator": 500, "admin": 0}
+)
+
+// Reputation, oyların yazara kazandırdığı puanlar ve puanla açılan yetkilerdir.
+// Ağırlıklar değişirse tüm puanlar sunucu açılışında yeniden hesaplanır.
+type Reputation struct {
+	Weights    map[string]int64 `json:"weights"`    // post_like, post_dislike, comment_like, comment_dislike, accepted_answer
+	Privileges map[string]int64 `json:"privileges"` // Yetki için gereken en düşük puan: downvote, post_images
+}
+
+// Yapılandırmada verilmeyen puan ağırlıkları ve yetki eşikleri için varsayılanlar
+var (
+	DefaultReputationWeights = map[string]int64{"post_like": 10, "post_dislike": -2, "comment_like": 5, "comment_dislike": -1, "accepted_answer": 15}
+	DefaultPrivileges        = map[string]int64{"downvote": 15, "post_images": 5}
+)
+
+// Storage, yüklenen dosyaların nerede saklanacağını belirler.
+type Storage struct {
+	Backend  string `json:"backend"`   // "local" (varsayılan) veya "s3"
+	LocalDir string `json:"local_dir"` // Yerel depo dizini, varsayılan ./uploads
+	S3       S3     `json:"s3"`
+}
+
+// S3, S3 uyumlu (AWS S3, MinIO vb.) nesne deposunun bağlantı bilgileridir.
+type S3 struct {
+	Endpoint        string `json:"endpoint"` // ör. https://s3.eu-central-1.amazonaws.com veya http://localhost:9000
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	PathStyle       bool   `json:"path_style"` // MinIO gibi depolar için /bucket/anahtar adresleme
+	PublicURL       string `json:"public_url"` // Boş değilse herkese açık dosyalar bu adresten (CDN vb.) sunulur
+}
+
+// Load, yapılandırma dosyasını okur ve boş alanlara varsayılanları uygular.
+// S3 anahtarları dosyaya yazılmak istenmezse S3_ACCESS_KEY_ID ve S3_SECRET_ACCESS_KEY
+// ortam değişkenleriyle verilebilir; ortam değişkenleri dosyadaki değerleri ezer.
+func Load(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	var cfg Config
+	if err := json.NewDecoder(file).Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	if cfg.Storage.Backend == "" {
+		cfg.Storage.Backend = "local"
+	}
+	if cfg.Storage.LocalDir == "" {
+		cfg.Storage.LocalDir = "./uploads"
+	}
+	if cfg.Storage.S3.Region == "" {
+		cfg.Storage.S3.Region = "us-east-1"
+	}
+	cfg.Uploads.MaxFileSizeMB = withDefaults(cfg.Uploads.MaxFileSizeMB, DefaultMaxFileSizeMB)
+	cfg.Uploads.QuotaMB = withDefaults(cfg.Uploads.QuotaMB, DefaultQuotaMB)
+	for kind, size := range cfg.Uploads.MaxFileSizeMB {
+		if _, ok := DefaultMaxFileSizeMB[kind]; !ok || size <= 0 {
+			return nil, fmt.Errorf("invalid uploads.max_file_size_mb entry %q: %d", kind, size)
+		}
+	}
+	for role, quota := range cfg.Uploads.QuotaMB {
+		if quota < 0 {
+			return nil, fmt.Errorf("invalid uploads.quota_mb entry %q: %d", role, quota)
+		}
+	}
+	cfg.Reputation.Weights = withDefaults(cfg.Reputation.Weights, DefaultReputationWeights)
+	cfg.Reputation.Privileges = withDefaults(cfg.Reputation.Privileges, DefaultPrivileges)
+	for name := range cfg.Reputation.Weights {
+		if _, ok := DefaultReputationWeights[name]; !ok {
+			return nil, fmt.Errorf("invalid reputation.weights entry %q", name)
+		}
+	}
+	for name, min := range cfg.Reputation.Privileges {
+		if _, ok := DefaultPrivileges[name]; !ok || min < 0 {
+			return nil, fmt.Errorf("invalid reputation.privileges entry %q: %d", name, min)
+		}
+	}
+	if err := cfg.Mail.withDefaults(); err != nil {
+		return nil, err
+	}
+	if v := os.Getenv("S3_ACCESS_KEY_ID"); v != "" {
+		cfg.Storage.S3.AccessKeyID = v
+	}
+	if v := os.Getenv("S3_SECRET_ACCESS_KEY"); v != "" {
+		cfg.Storage.S3.SecretAccessKey = v
+	}
+	if v := os.Getenv("SMTP_PASSWORD"); v != "" {
+		cfg.Mail.SMTP.Password = v
+	}
+	return &cfg, nil
+}
+
+// Eksik anahtarları varsayılan değerlerle doldurur.
+func withDefaults(values, defaults map[string]int64) map[string]int64 {
+	merged := make(map[string]int64, len(defaults))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+	return merged
+}
+
+// E-posta ayarlarının boş alanlarını doldurur ve sürücüyü doğrular.
+func (m *Mail) withDefaults() error {
+	switch m.Driver {
+	case "", "file":
+	case "smtp":
+		if m.SMTP.Host == "" {
+			return fmt.Errorf("mail.smtp.host is required for the smtp driver")
+		}
+	default:
+		return fmt.Errorf("invalid mail.driver %q (expected smtp or file)", m.Driver)
+	}
+	if m.From == "" {
+		m.From = "forum@localhost"
+	}
+	if m.BaseURL == "" {
+		m.BaseURL = "http://localhost:8065"
+	}
+	m.BaseURL = strings.TrimRight(m.BaseURL, "/")
+	if m.Dir == "" {
+		m.Dir = "./outbox"
+	}
+	if m.SMTP.Port == 0 {
+		m.SMTP.Port = 587
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Attachment, depoya yüklenmiş bir dosyanın kaydıdır.
+type Attachment struct {
+	ID           int64
+	StorageKey   string
+	OriginalName string
+	ContentType  string
+	Size         int64
+	Width        int
+	Height       int
+	UserID       int // Yükleyen kullanıcı; bilinmiyorsa 0
+	CommentID    int // Yoruma bağlıysa yorumun ID'si
+	Position     int // Gönderi veya yorumdaki sırası (0'dan başlar)
+	Caption      string
+	CreatedAt    time.Time
+	OrphanedAt   sql.NullTime
+}
+
+// IsImage, ekin galeri içinde görsel olarak gösterilip gösterilmeyeceğini belirtir.
+func (a Attachment) IsImage() bool {
+	return strings.HasPrefix(a.ContentType, "image/")
+}
+
+// SizeLabel, dosya boyutunu okunabilir biçimde döndürür (ör. "1.2 MB").
+func (a Attachment) SizeLabel() string {
+	return FormatSize(a.Size)
+}
+
+// AttachmentLink, bir gönderiye veya yoruma bağlanacak ek ve açıklamasıdır; sırası dilimdeki sırasıdır.
+type AttachmentLink struct {
+	ID      int64
+	Caption string
+}
+
+const attachmentColumns = `a.id, a.storage_key, COALESCE(a.original_name, ''), COALESCE(a.content_type, ''), a.size,
+	a.width, a.height, COALESCE(a.user_id, 0), COALESCE(a.comment_id, 0), a.position, a.caption, a.created_at, a.orphaned_at`
+
+func scanAttachments(rows *sql.Rows) ([]Attachment, error) {
+	defer rows.Close()
+
+	var attachments []Attachment
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(&a.ID, &a.StorageKey, &a.OriginalName, &a.ContentType, &a.Size, &a.Width, &a.Height,
+			&a.UserID, &a.CommentID, &a.Position, &a.Caption, &a.CreatedAt, &a.OrphanedAt); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// Bir eki kullanan canlı bir kayıt (silinmemiş gönderi, yorum veya profil) var mı?
+const attachmentReferenced = `(
+	EXISTS (SELECT 1 FROM posts p WHERE p.deleted = 0 AND (p.id = a.post_id OR p.image_path = a.storage_key))
+	OR EXISTS (SELECT 1 FROM comments c JOIN posts p ON p.id = c.post_id
+		WHERE c.deleted = 0 AND p.deleted = 0 AND (c.id = a.comment_id OR c.image_path = a.storage_key))
+	OR EXISTS (SELECT 1 FROM users u WHERE u.profile_picture_path = a.storage_key)
+)`
+
+// CreateAttachment, yeni yüklenen dosyayı henüz hiçbir kayda bağlı olmadan kaydeder.
+// Bağlanmayan dosyalar çöp toplayıcı tarafından bekleme süresinden sonra silinir.
+func CreateAttachment(a Attachment) (int64, error) {
+	var userID interface{}
+	if a.UserID != 0 {
+		userID = a.UserID
+	}
+	res, err := DB.Exec(`INSERT INTO attachments (storage_key, original_name, content_type, size, width, height, user_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		a.StorageKey, a.OriginalName, a.ContentType, a.Size, a.Width, a.Height, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error saving attachment %s: %v", a.StorageKey, err)
+	}
+	return res.LastInsertId()
+}
+
+// Ekleri verilen sırayla ve açıklamalarıyla gönderiye ya da yoruma (column) bağlar; içeriği
+// ekleyen işlemin (tx) parçası olarak çalışır.
+func linkAttachments(tx *sql.Tx, column string, parentID int64, links []AttachmentLink) error {
+	for i, link := range links {
+		res, err := tx.Exec("UPDATE attachments SET "+column+" = ?, position = ?, caption = ?, orphaned_at = NULL WHERE id = ?",
+			parentID, i, link.Caption, link.ID)
+		if err := affectedOne(res, err); err != nil {
+			return fmt.Errorf("error linking attachment %d: %v", link.ID, err)
+		}
+	}
+	return nil
+}
+
+// PostAttachments, gönderinin eklerini sırasıyla döndürür.
+func PostAttachments(postID int) ([]Attachment, error) {
+	rows, err := DB.Query(`SELECT `+attachmentColumns+` FROM attachments a
+		WHERE a.post_id = ? ORDER BY a.position, a.id`, postID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAttachments(rows)
+}
+
+// CommentAttachments, gönderideki tüm yorumların eklerini yorum ID'sine göre gruplayarak döndürür.
+func CommentAttachments(postID int) (map[int][]Attachment, error) {
+	rows, err := DB.Query(`SELECT `+attachmentColumns+` FROM attachments a
+		JOIN comments c ON c.id = a.comment_id
+		WHERE c.post_id = ? ORDER BY a.comment_id, a.position, a.id`, postID)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := scanAttachments(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	byComment := make(map[int][]Attachment)
+	for _, a := range attachments {
+		byComment[a.CommentID] = append(byComment[a.CommentID], a)
+	}
+	return byComment, nil
+}
+
+// MarkOrphanedAttachments, sahipsiz kalan eklerin orphaned_at zamanını işaretler ve
+// yeniden kullanılmaya başlayanlarınkini temizler. İşaretlenen ek sayısını döndürür.
+func MarkOrphanedAttachments() (int64, error) {
+	if _, err := DB.Exec(`UPDATE attachments SET orphaned_at = NULL
+		WHERE orphaned_at IS NOT NULL AND id IN (SELECT a.id FROM attachments a WHERE ` + attachmentReferenced + `)`); err != nil {
+		return 0, err
+	}
+	res, err := DB.Exec(`UPDATE attachments SET orphaned_at = datetime('now')
+		WHERE orphaned_at IS NULL AND id IN (SELECT a.id FROM attachments a WHERE NOT ` + attachmentReferenced + `)`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// OrphanedAttachments, sahipsiz ekleri döndürür. grace sıfırdan büyükse yalnızca en az
+// bu kadar süredir sahipsiz olanlar döner; henüz işaretlenmemiş sahipsiz ekler de
+// (dry-run raporu için) includeUnmarked ile listelenebilir.
+func OrphanedAttachments(grace time.Duration, includeUnmarked bool) ([]Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments a
+		WHERE NOT ` + attachmentReferenced + ` AND (a.orphaned_at <= datetime('now', ?)`
+	if includeUnmarked {
+		query += " OR a.orphaned_at IS NULL"
+	}
+	query += ") ORDER BY a.id"
+
+	rows, err := DB.Query(query, fmt.Sprintf("-%d seconds", int64(grace.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+	return scanAttachments(rows)
+}
+
+// DeleteAttachment, ekin kaydını siler (dosya çağıran tarafından depodan silinir).
+func DeleteAttachment(id int64) error {
+	_, err := DB.Exec("DELETE FROM attachments WHERE id = ?", id)
+	return err
+}
+
+// KnownStorageKeys, kaydı olan veya bir gönderi, yorum ya da profil tarafından kullanılan
+// tüm depo anahtarlarını döndürür. Kaydı olmayan dosyalar bu kümeyle bulunur.
+func KnownStorageKeys() (map[string]bool, error) {
+	rows, err := DB.Query(`
+		SELECT storage_key FROM attachments
+		UNION SELECT image_path FROM posts WHERE image_path IS NOT NULL AND image_path != ''
+		UNION SELECT image_path FROM comments WHERE image_path IS NOT NULL AND image_path != ''
+		UNION SELECT profile_picture_path FROM users WHERE profile_picture_path IS NOT NULL AND profile_picture_path != ''`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys[key] = true
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,89 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UserBadge, kullanıcıya verilmiş bir rozettir.
+type UserBadge struct {
+	Badge     string
+	AwardedAt time.Time
+}
+
+// AwardBadge, rozeti kullanıcıya verir. Rozet zaten verilmişse false döner.
+func AwardBadge(userID int, badge string, at time.Time) (bool, error) {
+	res, err := DB.Exec("INSERT OR IGNORE INTO user_badges (user_id, badge, awarded_at) VALUES (?, ?, ?)", userID, badge, at)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// UserBadges, kullanıcının rozetlerini verilme sırasıyla döndürür.
+func UserBadges(userID int) ([]UserBadge, error) {
+	rows, err := DB.Query("SELECT badge, awarded_at FROM user_badges WHERE user_id = ? ORDER BY awarded_at, badge", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var badges []UserBadge
+	for rows.Next() {
+		var b UserBadge
+		if err := rows.Scan(&b.Badge, &b.AwardedAt); err != nil {
+			return nil, err
+		}
+		badges = append(badges, b)
+	}
+	return badges, rows.Err()
+}
+
+// UserIDs, tüm kullanıcıların ID'leridir.
+func UserIDs() ([]int, error) {
+	rows, err := DB.Query("SELECT id FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// MemberSince, kullanıcının kayıt tarihidir.
+func MemberSince(userID int) (sql.NullTime, error) {
+	var since sql.NullTime
+	err := DB.QueryRow("SELECT created_at FROM users WHERE id = ?", userID).Scan(&since)
+	return since, err
+}
+
+// FirstPostAt, kullanıcının silinmemiş ilk gönderisinin tarihidir; gönderisi yoksa geçersizdir.
+func FirstPostAt(userID int) (sql.NullTime, error) {
+	var at sql.NullTime
+	err := DB.QueryRow("SELECT created_at FROM posts WHERE user_id = ? AND deleted = 0 ORDER BY created_at LIMIT 1", userID).Scan(&at)
+	if err == sql.ErrNoRows {
+		return at, nil
+	}
+	return at, err
+}
+
+// LikesReceived, kullanıcının silinmemiş gönderi ve yorumlarına başkalarınca verilen beğenilerin sayısıdır.
+func LikesReceived(userID int) (int, error) {
+	var count int
+	err := DB.QueryRow(`SELECT COUNT(*) FROM votes v
+		LEFT JOIN posts p ON p.id = v.post_id AND v.comment_id IS NULL
+		LEFT JOIN comments c ON c.id = v.comment_id
+		WHERE v.vote_type = 1 AND v.user_id != ?
+			AND ((p.user_id = ? AND p.deleted = 0) OR (c.user_id = ? AND c.deleted = 0))`,
+		userID, userID, userID).Scan(&count)
+	return count, err
+}
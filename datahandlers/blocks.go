@@ -0,0 +1,29 @@
+package datahandlers
+
+// BlockUser, blocker kullanıcısının blocked kullanıcısını engellemesini kaydeder.
+func BlockUser(blockerID, blockedID int) error {
+	_, err := DB.Exec("INSERT OR IGNORE INTO user_blocks (blocker_id, blocked_id) VALUES (?, ?)", blockerID, blockedID)
+	return err
+}
+
+// UnblockUser, engeli kaldırır.
+func UnblockUser(blockerID, blockedID int) error {
+	_, err := DB.Exec("DELETE FROM user_blocks WHERE blocker_id = ? AND blocked_id = ?", blockerID, blockedID)
+	return err
+}
+
+// HasBlocked, blocker kullanıcısının blocked kullanıcısını engelleyip engellemediğini döndürür.
+func HasBlocked(blockerID, blockedID int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow("SELECT EXISTS (SELECT 1 FROM user_blocks WHERE blocker_id = ? AND blocked_id = ?)",
+		blockerID, blockedID).Scan(&exists)
+	return exists, err
+}
+
+// EitherBlocked, iki kullanıcıdan birinin diğerini engelleyip engellemediğini döndürür.
+func EitherBlocked(a, b int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM user_blocks
+		WHERE (blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?))`, a, b, b, a).Scan(&exists)
+	return exists, err
+}
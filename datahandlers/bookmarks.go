@@ -0,0 +1,261 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Yer imi sınırları
+const (
+	MaxBookmarkNoteLength   = 500
+	MaxBookmarkFolderLength = 50
+	MaxBookmarkFolders      = 50
+)
+
+var (
+	// ErrBookmarkFolderExists, kullanıcının aynı adda bir klasörü olduğunda döner.
+	ErrBookmarkFolderExists = errors.New("bookmark folder already exists")
+	// ErrTooManyBookmarkFolders, kullanıcı MaxBookmarkFolders sınırına ulaştığında döner.
+	ErrTooManyBookmarkFolders = fmt.Errorf("you can have at most %d bookmark folders", MaxBookmarkFolders)
+)
+
+// Bookmark, kullanıcının kaydettiği bir gönderi ya da yorumdur (CommentID 0 ise gönderi).
+type Bookmark struct {
+	ID        int64     `json:"id"`
+	PostID    int64     `json:"post_id"`
+	CommentID int64     `json:"comment_id,omitempty"`
+	FolderID  int64     `json:"folder_id,omitempty"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Listelenirken doldurulur
+	Folder    string `json:"folder,omitempty"`
+	PostTitle string `json:"post_title"`
+	Author    string `json:"author"`            // Gönderinin ya da yorumun yazarı
+	Excerpt   string `json:"excerpt,omitempty"` // Yorumun ilk satırları
+}
+
+// URL, yer iminin gösterdiği gönderi ya da yorumun adresidir.
+func (b Bookmark) URL() string {
+	if b.CommentID != 0 {
+		return fmt.Sprintf("/viewPost?id=%d#comment-%d", b.PostID, b.CommentID)
+	}
+	return fmt.Sprintf("/viewPost?id=%d", b.PostID)
+}
+
+// BookmarkFolder, kullanıcının yer imlerini grupladığı klasördür.
+type BookmarkFolder struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"` // Klasördeki görünür yer imi sayısı
+}
+
+// Yer imi listelerinde silinmiş gönderi ve yorumlar atlanır.
+const visibleBookmarkCondition = `p.deleted = 0 AND (b.comment_id IS NULL OR c.deleted = 0)`
+
+// SaveBookmark, gönderiyi (commentID 0 ise) ya da yorumu kaydeder. Zaten kayıtlıysa klasörü ve
+// notu güncellenir. Yorumun gönderiye ait olduğu ve klasörün kullanıcıya ait olduğu denetlenir;
+// bulunamazlarsa sql.ErrNoRows döner.
+func SaveBookmark(userID int, postID, commentID, folderID int64, note string) (int64, error) {
+	var exists bool
+	var err error
+	if commentID != 0 {
+		err = DB.QueryRow("SELECT EXISTS (SELECT 1 FROM comments WHERE id = ? AND post_id = ? AND deleted = 0)",
+			commentID, postID).Scan(&exists)
+	} else {
+		err = DB.QueryRow("SELECT EXISTS (SELECT 1 FROM posts WHERE id = ? AND deleted = 0)", postID).Scan(&exists)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, sql.ErrNoRows
+	}
+	if err := checkBookmarkFolder(userID, folderID); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = DB.QueryRow(`INSERT INTO bookmarks (user_id, post_id, comment_id, folder_id, note) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, post_id, COALESCE(comment_id, 0)) DO UPDATE SET folder_id = excluded.folder_id, note = excluded.note
+		RETURNING id`, userID, postID, nullID(commentID), nullID(folderID), note).Scan(&id)
+	return id, err
+}
+
+// UpdateBookmark, kullanıcının yer iminin klasörünü ve notunu değiştirir; yer imi ya da klasör
+// kullanıcıya ait değilse sql.ErrNoRows döner.
+func UpdateBookmark(userID int, id, folderID int64, note string) error {
+	if err := checkBookmarkFolder(userID, folderID); err != nil {
+		return err
+	}
+	res, err := DB.Exec("UPDATE bookmarks SET folder_id = ?, note = ? WHERE id = ? AND user_id = ?",
+		nullID(folderID), note, id, userID)
+	return affectedOne(res, err)
+}
+
+// DeleteBookmark, kullanıcının yer imini siler; yer imi kullanıcıya ait değilse sql.ErrNoRows döner.
+func DeleteBookmark(userID int, id int64) error {
+	res, err := DB.Exec("DELETE FROM bookmarks WHERE id = ? AND user_id = ?", id, userID)
+	return affectedOne(res, err)
+}
+
+// UserBookmark, kullanıcının tek bir yer imini döndürür.
+func UserBookmark(userID int, id int64) (*Bookmark, error) {
+	bookmarks, err := queryBookmarks("b.user_id = ? AND b.id = ?", userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(bookmarks) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &bookmarks[0], nil
+}
+
+// UserBookmarks, kullanıcının yer imlerini yeniden eskiye döndürür; folderID 0 değilse yalnızca
+// o klasördekiler listelenir.
+func UserBookmarks(userID int, folderID int64) ([]Bookmark, error) {
+	if folderID != 0 {
+		return queryBookmarks("b.user_id = ? AND b.folder_id = ? ORDER BY b.created_at DESC, b.id DESC", userID, folderID)
+	}
+	return queryBookmarks("b.user_id = ? ORDER BY b.created_at DESC, b.id DESC", userID)
+}
+
+func queryBookmarks(where string, args ...interface{}) ([]Bookmark, error) {
+	rows, err := DB.Query(`SELECT b.id, b.post_id, COALESCE(b.comment_id, 0), COALESCE(b.folder_id, 0), b.note, b.created_at,
+			COALESCE(f.name, ''), p.title, COALESCE(u.username, ''), COALESCE(substr(c.content, 1, 200), '')
+		FROM bookmarks b
+		JOIN posts p ON p.id = b.post_id
+		LEFT JOIN comments c ON c.id = b.comment_id
+		LEFT JOIN bookmark_folders f ON f.id = b.folder_id
+		LEFT JOIN users u ON u.id = COALESCE(c.user_id, p.user_id)
+		WHERE `+visibleBookmarkCondition+` AND `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookmarks []Bookmark
+	for rows.Next() {
+		var b Bookmark
+		if err := rows.Scan(&b.ID, &b.PostID, &b.CommentID, &b.FolderID, &b.Note, &b.CreatedAt,
+			&b.Folder, &b.PostTitle, &b.Author, &b.Excerpt); err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, rows.Err()
+}
+
+// PostBookmarks, kullanıcının gönderide kaydettiği içerikleri yorum ID'sine göre döndürür
+// (gönderinin kendisi 0 anahtarıyla); değer yer iminin ID'sidir.
+func PostBookmarks(userID int, postID int64) (map[int64]int64, error) {
+	rows, err := DB.Query("SELECT COALESCE(comment_id, 0), id FROM bookmarks WHERE user_id = ? AND post_id = ?", userID, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	saved := make(map[int64]int64)
+	for rows.Next() {
+		var commentID, id int64
+		if err := rows.Scan(&commentID, &id); err != nil {
+			return nil, err
+		}
+		saved[commentID] = id
+	}
+	return saved, rows.Err()
+}
+
+// BookmarkFolders, kullanıcının klasörlerini adlarına göre sıralı döndürür.
+func BookmarkFolders(userID int) ([]BookmarkFolder, error) {
+	rows, err := DB.Query(`SELECT f.id, f.name,
+			(SELECT COUNT(*) FROM bookmarks b JOIN posts p ON p.id = b.post_id LEFT JOIN comments c ON c.id = b.comment_id
+				WHERE b.folder_id = f.id AND `+visibleBookmarkCondition+`)
+		FROM bookmark_folders f WHERE f.user_id = ? ORDER BY f.name COLLATE NOCASE`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var folders []BookmarkFolder
+	for rows.Next() {
+		var f BookmarkFolder
+		if err := rows.Scan(&f.ID, &f.Name, &f.Count); err != nil {
+			return nil, err
+		}
+		folders = append(folders, f)
+	}
+	return folders, rows.Err()
+}
+
+// CreateBookmarkFolder, kullanıcı için yeni bir klasör oluşturur.
+func CreateBookmarkFolder(userID int, name string) (int64, error) {
+	var count int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM bookmark_folders WHERE user_id = ?", userID).Scan(&count); err != nil {
+		return 0, err
+	}
+	if count >= MaxBookmarkFolders {
+		return 0, ErrTooManyBookmarkFolders
+	}
+	res, err := DB.Exec("INSERT OR IGNORE INTO bookmark_folders (user_id, name) VALUES (?, ?)", userID, name)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return 0, ErrBookmarkFolderExists
+	}
+	return res.LastInsertId()
+}
+
+// RenameBookmarkFolder, kullanıcının klasörünün adını değiştirir.
+func RenameBookmarkFolder(userID int, id int64, name string) error {
+	var taken bool
+	err := DB.QueryRow("SELECT EXISTS (SELECT 1 FROM bookmark_folders WHERE user_id = ? AND name = ? AND id != ?)",
+		userID, name, id).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrBookmarkFolderExists
+	}
+	res, err := DB.Exec("UPDATE bookmark_folders SET name = ? WHERE id = ? AND user_id = ?", name, id, userID)
+	return affectedOne(res, err)
+}
+
+// DeleteBookmarkFolder, kullanıcının klasörünü siler; içindeki yer imleri bookmarks.folder_id
+// yabancı anahtarının ON DELETE SET NULL kuralıyla klasörsüz kalır.
+func DeleteBookmarkFolder(userID int, id int64) error {
+	res, err := DB.Exec("DELETE FROM bookmark_folders WHERE id = ? AND user_id = ?", id, userID)
+	return affectedOne(res, err)
+}
+
+// Klasör verilmişse kullanıcıya ait olduğunu denetler.
+func checkBookmarkFolder(userID int, folderID int64) error {
+	if folderID == 0 {
+		return nil
+	}
+	var exists bool
+	err := DB.QueryRow("SELECT EXISTS (SELECT 1 FROM bookmark_folders WHERE id = ? AND user_id = ?)", folderID, userID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Tek bir satırı değiştirmesi beklenen sorgularda hiçbir satır değişmediyse sql.ErrNoRows döndürür.
+func affectedOne(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package datahandlers
+
+import "testing"
+
+func TestDeleteBookmarkFolderKeepsBookmarks(t *testing.T) {
+	openMigratedDB(t)
+	_, err := DB.Exec(`INSERT INTO users (id, email, username, password) VALUES (1, 'a@example.com', 'alice', 'x');
+		INSERT INTO posts (id, user_id, title) VALUES (1, 1, 'x');`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	folderID, err := CreateBookmarkFolder(1, "Read later")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DB.Exec("INSERT INTO bookmarks (user_id, post_id, folder_id) VALUES (1, 1, ?)", folderID); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteBookmarkFolder(2, folderID); err == nil {
+		t.Error("DeleteBookmarkFolder deleted another user's folder")
+	}
+	if err := DeleteBookmarkFolder(1, folderID); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM bookmarks WHERE folder_id IS NULL").Scan(&n); err != nil || n != 1 {
+		t.Errorf("bookmarks without a folder = %d, %v; want 1", n, err)
+	}
+}
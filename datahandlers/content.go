@@ -0,0 +1,45 @@
+package datahandlers
+
+import (
+	"html/template"
+	"log"
+
+	"form-project/markdown"
+)
+
+// RenderedContent, içeriğin HTML'i ve kaydedilecek işleyici sürümüdür; gönderi ve yorum
+// eklenirken content_html ve content_version sütunlarına yazılır. Yalnızca var olan
+// kullanıcılardan bahseden @ifadeler profile bağlanır.
+func RenderedContent(source string) (string, int) {
+	users, err := FindMentions(source)
+	if err != nil {
+		log.Printf("error finding mentions: %v", err)
+	}
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Username
+	}
+	return string(markdown.Render(source, names...)), markdown.Version
+}
+
+// PostHTML, gönderinin önbellekteki HTML'ini döndürür; önbellek eskiyse yeniden işleyip günceller.
+func PostHTML(postID int, source, cached string, version int) template.HTML {
+	return contentHTML("posts", postID, source, cached, version)
+}
+
+// CommentHTML, yorumun önbellekteki HTML'ini döndürür; önbellek eskiyse yeniden işleyip günceller.
+func CommentHTML(commentID int, source, cached string, version int) template.HTML {
+	return contentHTML("comments", commentID, source, cached, version)
+}
+
+func contentHTML(table string, id int, source, cached string, version int) template.HTML {
+	if version == markdown.Version {
+		return template.HTML(cached)
+	}
+	html, version := RenderedContent(source)
+	// Önbelleğe yazılamazsa sayfa yine gösterilir; bir sonraki okumada yeniden denenir
+	if _, err := DB.Exec("UPDATE "+table+" SET content_html = ?, content_version = ? WHERE id = ?", html, version, id); err != nil {
+		log.Printf("error caching rendered content of %s %d: %v", table, id, err)
+	}
+	return template.HTML(html)
+}
@@ -0,0 +1,54 @@
+package datahandlers
+
+import (
+	"strings"
+	"testing"
+
+	"form-project/markdown"
+)
+
+func openTestDB(t *testing.T, schema string) {
+	t.Helper()
+	db, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bellek içi veritabanı bağlantıya özeldir
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatal(err)
+	}
+	old := DB
+	DB = db
+	t.Cleanup(func() {
+		DB = old
+		db.Close()
+	})
+}
+
+func TestPostHTMLCachedVersion(t *testing.T) {
+	openTestDB(t, `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, username TEXT, mention_privacy TEXT DEFAULT 'everyone');
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, content TEXT, content_html TEXT, content_version INTEGER);
+		INSERT INTO users (id, username) VALUES (1, 'alice');
+		INSERT INTO posts VALUES (1, '**hi** @alice', '<p>cached</p>', 0);`)
+
+	// Güncel sürümle önbelleğe alınmış HTML olduğu gibi döner
+	if got := PostHTML(1, "**hi** @alice", "<p>cached</p>", markdown.Version); got != "<p>cached</p>" {
+		t.Errorf("PostHTML with current version = %q, want cached HTML", got)
+	}
+
+	// Eski sürüm yeniden işlenir ve önbellek güncellenir
+	got := string(PostHTML(1, "**hi** @alice", "<p>cached</p>", markdown.Version-1))
+	if !strings.Contains(got, "<strong>hi</strong>") || !strings.Contains(got, `href="/u/alice"`) {
+		t.Errorf("PostHTML with stale version = %q, want freshly rendered HTML", got)
+	}
+	var html string
+	var version int
+	if err := DB.QueryRow("SELECT content_html, content_version FROM posts WHERE id = 1").Scan(&html, &version); err != nil {
+		t.Fatal(err)
+	}
+	if html != got || version != markdown.Version {
+		t.Errorf("cache = %q (version %d), want %q (version %d)", html, version, got, markdown.Version)
+	}
+}
@@ -0,0 +1,202 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Gönderi ve yorumlardaki like_count, dislike_count ve comment_count sütunları listelemede oyları
+// ve yorumları her istekte saymamak için tutulur. Oy ve yorum yazan işlemler sayaçları aynı
+// işlem (tx) içinde günceller; ReconcileCounters kaynak tablolardan baştan hesaplar.
+
+// Tüm sayaçların kaynak tablolardan hesaplanmış değerleri
+const (
+	postCounts = `SELECT posts.id,
+			(SELECT COUNT(*) FROM votes WHERE votes.post_id = posts.id AND votes.comment_id IS NULL AND votes.vote_type = 1) AS likes,
+			(SELECT COUNT(*) FROM votes WHERE votes.post_id = posts.id AND votes.comment_id IS NULL AND votes.vote_type = -1) AS dislikes,
+			(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted = 0) AS comments
+		FROM posts`
+	commentCounts = `SELECT comments.id,
+			(SELECT COUNT(*) FROM votes WHERE votes.comment_id = comments.id AND votes.vote_type = 1) AS likes,
+			(SELECT COUNT(*) FROM votes WHERE votes.comment_id = comments.id AND votes.vote_type = -1) AS dislikes
+		FROM comments`
+)
+
+// RecordVote, kullanıcının gönderi veya yorumdaki oyunu voteType (1/-1) olarak kaydeder; aynı oy
+// tekrar verilirse geri alır. Mevcut oy, oy ve içeriğin beğeni/beğenmeme sayaçları aynı işlemde
+// okunup yazılır. Önceki ve yeni oy döner (oy yoksa 0).
+func RecordVote(userID int, postID, commentID int64, voteType int) (oldVote, newVote int, err error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	column, table, id := "post_id", "posts", postID
+	if commentID != 0 {
+		column, table, id = "comment_id", "comments", commentID
+	}
+	// Gönderi oylarında comment_id boştur; votes tablosundaki tekil indekslerle aynı koşul
+	target := "user_id = ? AND " + column + " = ?"
+	if commentID == 0 {
+		target += " AND comment_id IS NULL"
+	}
+
+	err = tx.QueryRow("SELECT vote_type FROM votes WHERE "+target, userID, id).Scan(&oldVote)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, 0, err
+	}
+	newVote = voteType
+	if oldVote == voteType {
+		newVote = 0
+	}
+
+	var res sql.Result
+	switch {
+	case oldVote == 0:
+		res, err = tx.Exec("INSERT INTO votes (user_id, "+column+", vote_type) VALUES (?, ?, ?)", userID, id, newVote)
+	case newVote == 0:
+		res, err = tx.Exec("DELETE FROM votes WHERE "+target, userID, id)
+	default:
+		res, err = tx.Exec("UPDATE votes SET vote_type = ? WHERE "+target, newVote, userID, id)
+	}
+	if err := affectedOne(res, err); err != nil {
+		return 0, 0, err
+	}
+
+	likes, dislikes := voteDelta(newVote, 1)-voteDelta(oldVote, 1), voteDelta(newVote, -1)-voteDelta(oldVote, -1)
+	res, err = tx.Exec("UPDATE "+table+" SET like_count = like_count + ?, dislike_count = dislike_count + ? WHERE id = ?",
+		likes, dislikes, id)
+	if err := affectedOne(res, err); err != nil {
+		return 0, 0, err
+	}
+	return oldVote, newVote, tx.Commit()
+}
+
+func voteDelta(vote, voteType int) int {
+	if vote == voteType {
+		return 1
+	}
+	return 0
+}
+
+// VoteCounts, gönderinin ya da (commentID verilmişse) yorumun beğeni ve beğenmeme sayılarını döndürür.
+func VoteCounts(postID, commentID int64) (likes, dislikes int, err error) {
+	if commentID != 0 {
+		err = DB.QueryRow("SELECT like_count, dislike_count FROM comments WHERE id = ?", commentID).Scan(&likes, &dislikes)
+	} else {
+		err = DB.QueryRow("SELECT like_count, dislike_count FROM posts WHERE id = ?", postID).Scan(&likes, &dislikes)
+	}
+	return likes, dislikes, err
+}
+
+// NewComment, CreateComment ile eklenecek yorumdur.
+type NewComment struct {
+	PostID      int64
+	UserID      int
+	Content     string
+	ImagePath   string
+	Attachments []AttachmentLink // Yoruma sırasıyla bağlanacak ekler
+}
+
+// CreateComment, yorumu ekler; eklerini bağlar ve gönderinin yorum sayacını aynı işlemde artırır.
+// Yorumun ID'si döner; gönderi yoksa veya silinmişse sql.ErrNoRows döner.
+func CreateComment(c NewComment) (int64, error) {
+	contentHTML, contentVersion := RenderedContent(c.Content)
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO comments (post_id, user_id, content, content_html, content_version, created_at, image_path)
+		SELECT ?, ?, ?, ?, ?, ?, ? WHERE EXISTS (SELECT 1 FROM posts WHERE id = ? AND deleted = 0)`,
+		c.PostID, c.UserID, c.Content, contentHTML, contentVersion, time.Now(), c.ImagePath, c.PostID)
+	if err := affectedOne(res, err); err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := linkAttachments(tx, "comment_id", id, c.Attachments); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec("UPDATE posts SET comment_count = comment_count + 1 WHERE id = ?", c.PostID); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// DeleteComment, yorumu silinmiş olarak işaretler ve gönderinin yorum sayacını aynı işlemde
+// azaltır. Yorum zaten silinmişse bir şey yapılmaz.
+func DeleteComment(commentID int64) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE comments SET deleted = 1 WHERE id = ? AND deleted = 0", commentID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	_, err = tx.Exec("UPDATE posts SET comment_count = comment_count - 1 WHERE id = (SELECT post_id FROM comments WHERE id = ?)", commentID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CounterReport, ReconcileCounters'ın özetidir: değerleri düzeltilen gönderi ve yorum sayıları.
+type CounterReport struct {
+	Posts    int64
+	Comments int64
+}
+
+// ReconcileCounters, tüm gönderi ve yorum sayaçlarını oylardan ve yorumlardan baştan hesaplar;
+// gönderi sayaçları düzeltildiyse sıralama puanlarını da yeniler. dryRun ise değişiklik yapılmaz, yalnızca tutarsız satırlar sayılır.
+func ReconcileCounters(dryRun bool) (CounterReport, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return CounterReport{}, err
+	}
+	defer tx.Rollback()
+
+	report, err := reconcileCounters(tx)
+	if err != nil || dryRun {
+		return report, err
+	}
+	// Sıralama puanları sayaçlardan hesaplanır
+	if report.Posts > 0 {
+		if err := refreshPostScores(tx, "1 = 1"); err != nil {
+			return report, err
+		}
+	}
+	return report, tx.Commit()
+}
+
+func reconcileCounters(tx *sql.Tx) (CounterReport, error) {
+	var report CounterReport
+	res, err := tx.Exec(`UPDATE posts SET like_count = t.likes, dislike_count = t.dislikes, comment_count = t.comments
+		FROM (` + postCounts + `) t
+		WHERE t.id = posts.id AND (posts.like_count != t.likes OR posts.dislike_count != t.dislikes OR posts.comment_count != t.comments)`)
+	if err != nil {
+		return report, err
+	}
+	if report.Posts, err = res.RowsAffected(); err != nil {
+		return report, err
+	}
+
+	res, err = tx.Exec(`UPDATE comments SET like_count = t.likes, dislike_count = t.dislikes
+		FROM (` + commentCounts + `) t
+		WHERE t.id = comments.id AND (comments.like_count != t.likes OR comments.dislike_count != t.dislikes)`)
+	if err != nil {
+		return report, err
+	}
+	report.Comments, err = res.RowsAffected()
+	return report, err
+}
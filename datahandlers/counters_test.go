@@ -0,0 +1,32 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestCreateCommentRequiresPost(t *testing.T) {
+	openMigratedDB(t)
+	_, err := DB.Exec(`INSERT INTO users (id, email, username, password) VALUES (1, 'a@example.com', 'alice', 'x');
+		INSERT INTO posts (id, user_id, title) VALUES (1, 1, 'live'), (2, 1, 'removed');
+		UPDATE posts SET deleted = 1 WHERE id = 2;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, postID := range []int64{2, 99} {
+		if _, err := CreateComment(NewComment{PostID: postID, UserID: 1, Content: "x"}); err != sql.ErrNoRows {
+			t.Errorf("CreateComment on post %d: err = %v, want sql.ErrNoRows", postID, err)
+		}
+	}
+	if _, err := CreateComment(NewComment{PostID: 1, UserID: 1, Content: "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var comments, count int
+	DB.QueryRow("SELECT COUNT(*) FROM comments").Scan(&comments)
+	DB.QueryRow("SELECT comment_count FROM posts WHERE id = 1").Scan(&count)
+	if comments != 1 || count != 1 {
+		t.Errorf("comments = %d, comment_count = %d; want 1 and 1", comments, count)
+	}
+}
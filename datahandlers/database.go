@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"time"
 
+	"form-project/metrics"
+
 	"golang.org/x/crypto/bcrypt" // Bcrypt paketi ile şifre hash'leme
 )
 
@@ -21,14 +23,22 @@ type Session struct {
 // Veritabanına bağlantı açar.
 func SetDB() {
 	var err error
-	DB, err = sql.Open("sqlite3", "./database/forum.db")
+	DB, err = Open("./database/forum.db")
 	if err != nil {
 		log.Fatal("Error opening database: ", err)
 	}
 
+	// Aktif oturum sayısını metriklere bildir
+	metrics.RegisterActiveSessions(countActiveSessions)
+
 	// Tabloları oluştur
 	CreateTables()
 
+	// Bekleyen şema değişikliklerini uygula
+	if err := Migrate(); err != nil {
+		log.Fatal("Error migrating database: ", err)
+	}
+
 	// Admin kullanıcısını kontrol et ve gerekirse oluştur
 	err = createAdminUserIfNotExists()
 	if err != nil {
@@ -36,6 +46,12 @@ func SetDB() {
 	}
 }
 
+// Open, SQLite veritabanını yabancı anahtar kısıtları açık olarak açar; tablolardaki
+// REFERENCES ... ON DELETE kuralları ancak bu şekilde uygulanır.
+func Open(path string) (*sql.DB, error) {
+	return sql.Open(instrumentedDriverName, path+"?_foreign_keys=1")
+}
+
 // Admin kullanıcısı yoksa oluşturur.
 func createAdminUserIfNotExists() error {
 	var count int
@@ -57,8 +73,8 @@ func createAdminUserIfNotExists() error {
 			return fmt.Errorf("error hashing password: %v", err)
 		}
 
-		_, err = DB.Exec("INSERT INTO users (email, username, password, role) VALUES (?, ?, ?, ?)",
-			email, username, hashedPassword, role)
+		_, err = DB.Exec("INSERT INTO users (email, username, password, role, created_at) VALUES (?, ?, ?, ?, ?)",
+			email, username, hashedPassword, role, time.Now())
 		if err != nil {
 			return fmt.Errorf("error creating admin user: %v", err)
 		}
@@ -111,6 +127,53 @@ func GetSession(r *http.Request) (*Session, error) {
 	return &session, nil
 }
 
+// Oturum açmış kullanıcının sayfalarda gösterilen temel bilgileri
+type SessionUser struct {
+	ID         int
+	Username   string
+	Email      string
+	Role       string
+	AvatarPath string // Profil fotoğrafı; yoksa boş
+}
+
+func (u *SessionUser) IsAdmin() bool {
+	return u != nil && u.Role == "admin"
+}
+
+// Moderatör yetkileri adminleri de kapsar.
+func (u *SessionUser) IsModerator() bool {
+	return u != nil && (u.Role == "moderator" || u.Role == "admin")
+}
+
+// İstekteki oturuma ait kullanıcıyı döndürür; oturum yoksa nil döner.
+func GetSessionUser(r *http.Request) (*SessionUser, error) {
+	session, err := GetSession(r)
+	if err != nil || session == nil {
+		return nil, err
+	}
+
+	var user SessionUser
+	var username sql.NullString
+	err = DB.QueryRow("SELECT id, username, email, role, COALESCE(profile_picture_path, '') FROM users WHERE id = ?", session.UserID).
+		Scan(&user.ID, &username, &user.Email, &user.Role, &user.AvatarPath)
+	if err != nil {
+		return nil, err
+	}
+	user.Username = username.String
+	return &user, nil
+}
+
+// Süresi dolmamış oturumların sayısını döndürür.
+func countActiveSessions() float64 {
+	var count int
+	err := DB.QueryRow("SELECT COUNT(*) FROM sessions WHERE expiry > ?", time.Now()).Scan(&count)
+	if err != nil {
+		log.Println("Error counting active sessions:", err)
+		return 0
+	}
+	return float64(count)
+}
+
 // Gerekli veritabanı tablolarını oluşturur.
 func CreateTables() {
 	SessionTables(DB)
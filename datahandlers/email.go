@@ -0,0 +1,148 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// E-posta bildirim modları
+const (
+	EmailOff       = "off"       // E-posta gönderilmez
+	EmailImmediate = "immediate" // Her teslim turunda bekleyen bildirimler gönderilir
+	EmailDaily     = "daily"     // Günde en fazla bir özet
+	EmailWeekly    = "weekly"    // Haftada en fazla bir özet
+)
+
+// Bir e-postada en fazla bu kadar bildirim listelenir; kalanlar bir sonrakine kalır.
+const maxEmailNotifications = 200
+
+// Bildirimin e-postaya girmesi için okunmamış, daha önce gönderilmemiş ve konusunun
+// aboneliğinden çıkılmamış olması gerekir.
+const pendingEmailCondition = `n.read_at IS NULL AND n.emailed_at IS NULL
+	AND NOT EXISTS (SELECT 1 FROM email_unsubscribes e WHERE e.user_id = n.user_id AND e.post_id = n.post_id)`
+
+// ValidEmailMode, modun geçerli olup olmadığını döndürür.
+func ValidEmailMode(mode string) bool {
+	switch mode {
+	case EmailOff, EmailImmediate, EmailDaily, EmailWeekly:
+		return true
+	}
+	return false
+}
+
+// EmailRecipient, bekleyen bildirimi olan ve e-posta almayı seçmiş bir kullanıcıdır.
+type EmailRecipient struct {
+	UserID   int
+	Username string
+	Email    string
+	Mode     string
+	DigestAt sql.NullTime // Son özetin gönderildiği zaman
+}
+
+// EmailMode, kullanıcının e-posta bildirim modunu döndürür.
+func EmailMode(userID int) (string, error) {
+	var mode string
+	err := DB.QueryRow("SELECT email_mode FROM users WHERE id = ?", userID).Scan(&mode)
+	return mode, err
+}
+
+// SetEmailMode, kullanıcının e-posta bildirim modunu değiştirir. E-posta yeni açılıyorsa
+// önceki bildirimler gönderilmiş sayılır; kullanıcı yalnızca bundan sonrakileri alır.
+func SetEmailMode(userID int, mode string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current string
+	if err := tx.QueryRow("SELECT email_mode FROM users WHERE id = ?", userID).Scan(&current); err != nil {
+		return err
+	}
+	if current == mode {
+		return nil
+	}
+	if _, err := tx.Exec("UPDATE users SET email_mode = ? WHERE id = ?", mode, userID); err != nil {
+		return err
+	}
+	if current == EmailOff {
+		now := time.Now()
+		if _, err := tx.Exec("UPDATE users SET email_digest_at = ? WHERE id = ?", now, userID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE notifications SET emailed_at = ? WHERE user_id = ? AND emailed_at IS NULL", now, userID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// EmailRecipients, e-posta almayı seçmiş ve bekleyen bildirimi olan kullanıcıları döndürür.
+func EmailRecipients() ([]EmailRecipient, error) {
+	rows, err := DB.Query(`SELECT u.id, u.username, u.email, u.email_mode, u.email_digest_at
+		FROM users u
+		WHERE u.email_mode != ? AND u.email != ''
+			AND EXISTS (SELECT 1 FROM notifications n WHERE n.user_id = u.id AND `+pendingEmailCondition+`)
+		ORDER BY u.id`, EmailOff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []EmailRecipient
+	for rows.Next() {
+		var r EmailRecipient
+		if err := rows.Scan(&r.UserID, &r.Username, &r.Email, &r.Mode, &r.DigestAt); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, rows.Err()
+}
+
+// PendingEmailNotifications, kullanıcıya e-postayla gönderilecek bildirimleri eskiden yeniye döndürür.
+func PendingEmailNotifications(userID int) ([]Notification, error) {
+	return queryNotifications("n.user_id = ? AND "+pendingEmailCondition+" ORDER BY n.created_at, n.id LIMIT ?",
+		userID, maxEmailNotifications)
+}
+
+// MarkNotificationsEmailed, bildirimleri e-postayla gönderilmiş olarak işaretler.
+func MarkNotificationsEmailed(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := []interface{}{time.Now()}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	_, err := DB.Exec("UPDATE notifications SET emailed_at = ? WHERE id IN ("+placeholders+")", args...)
+	return err
+}
+
+// SetEmailDigestSent, kullanıcıya son özetin gönderildiği zamanı kaydeder.
+func SetEmailDigestSent(userID int, at time.Time) error {
+	_, err := DB.Exec("UPDATE users SET email_digest_at = ? WHERE id = ?", at, userID)
+	return err
+}
+
+// UnsubscribeEmail, kullanıcının gönderiyle ilgili bildirimleri e-postayla almamasını sağlar;
+// postID 0 ise kullanıcının tüm e-posta bildirimleri kapatılır. Gönderi silinmişse yapılacak
+// bir şey yoktur.
+func UnsubscribeEmail(userID int, postID int64) error {
+	if postID == 0 {
+		return SetEmailMode(userID, EmailOff)
+	}
+	_, err := DB.Exec(`INSERT OR IGNORE INTO email_unsubscribes (user_id, post_id)
+		SELECT ?, ? WHERE EXISTS (SELECT 1 FROM posts WHERE id = ?) AND EXISTS (SELECT 1 FROM users WHERE id = ?)`,
+		userID, postID, postID, userID)
+	return err
+}
+
+// PostTitle, silinmemiş gönderinin başlığını döndürür.
+func PostTitle(postID int64) (string, error) {
+	var title string
+	err := DB.QueryRow("SELECT title FROM posts WHERE id = ? AND deleted = 0", postID).Scan(&title)
+	return title, err
+}
@@ -0,0 +1,104 @@
+package datahandlers
+
+// Bir kategori adı en fazla bu kadar karakter olabilir
+const MaxCategoryLength = 64
+
+// FollowPost, kullanıcının gönderiyi takip etmesini kaydeder; daha önce takipten çıkmışsa yeniden açar.
+func FollowPost(userID int, postID int64) error {
+	_, err := DB.Exec(`INSERT INTO post_follows (user_id, post_id, following) VALUES (?, ?, 1)
+		ON CONFLICT (user_id, post_id) DO UPDATE SET following = 1`, userID, postID)
+	return err
+}
+
+// AutoFollowPost, gönderiyi oluşturan veya yorum yapan kullanıcının gönderiyi takip etmesini
+// sağlar; kullanıcı gönderiyi takipten çıkmışsa değiştirmez.
+func AutoFollowPost(userID int, postID int64) error {
+	_, err := DB.Exec("INSERT OR IGNORE INTO post_follows (user_id, post_id) VALUES (?, ?)", userID, postID)
+	return err
+}
+
+// UnfollowPost, kullanıcıyı gönderinin takipçilerinden çıkarır.
+func UnfollowPost(userID int, postID int64) error {
+	_, err := DB.Exec(`INSERT INTO post_follows (user_id, post_id, following) VALUES (?, ?, 0)
+		ON CONFLICT (user_id, post_id) DO UPDATE SET following = 0`, userID, postID)
+	return err
+}
+
+// IsFollowingPost, kullanıcının gönderiyi takip edip etmediğini döndürür.
+func IsFollowingPost(userID int, postID int64) (bool, error) {
+	var exists bool
+	err := DB.QueryRow("SELECT EXISTS (SELECT 1 FROM post_follows WHERE user_id = ? AND post_id = ? AND following = 1)",
+		userID, postID).Scan(&exists)
+	return exists, err
+}
+
+// PostFollowers, gönderiyi takip eden kullanıcıları döndürür.
+func PostFollowers(postID int64) ([]int, error) {
+	return userIDs("SELECT user_id FROM post_follows WHERE post_id = ? AND following = 1 ORDER BY created_at", postID)
+}
+
+// FollowCategory, kullanıcının kategoriyi takip etmesini kaydeder.
+func FollowCategory(userID int, category string) error {
+	_, err := DB.Exec("INSERT OR IGNORE INTO category_follows (user_id, category) VALUES (?, ?)", userID, category)
+	return err
+}
+
+// UnfollowCategory, kategorinin takibini bırakır.
+func UnfollowCategory(userID int, category string) error {
+	_, err := DB.Exec("DELETE FROM category_follows WHERE user_id = ? AND category = ?", userID, category)
+	return err
+}
+
+// IsFollowingCategory, kullanıcının kategoriyi takip edip etmediğini döndürür.
+func IsFollowingCategory(userID int, category string) (bool, error) {
+	var exists bool
+	err := DB.QueryRow("SELECT EXISTS (SELECT 1 FROM category_follows WHERE user_id = ? AND category = ?)",
+		userID, category).Scan(&exists)
+	return exists, err
+}
+
+// CategoryFollowers, kategoriyi takip eden kullanıcıları döndürür.
+func CategoryFollowers(category string) ([]int, error) {
+	return userIDs("SELECT user_id FROM category_follows WHERE category = ? ORDER BY created_at", category)
+}
+
+// FollowUser, follower kullanıcısının followed kullanıcısını takip etmesini kaydeder.
+func FollowUser(followerID, followedID int) error {
+	_, err := DB.Exec("INSERT OR IGNORE INTO user_follows (follower_id, followed_id) VALUES (?, ?)", followerID, followedID)
+	return err
+}
+
+// UnfollowUser, kullanıcının takibini bırakır.
+func UnfollowUser(followerID, followedID int) error {
+	_, err := DB.Exec("DELETE FROM user_follows WHERE follower_id = ? AND followed_id = ?", followerID, followedID)
+	return err
+}
+
+// IsFollowingUser, follower kullanıcısının followed kullanıcısını takip edip etmediğini döndürür.
+func IsFollowingUser(followerID, followedID int) (bool, error) {
+	var exists bool
+	err := DB.QueryRow("SELECT EXISTS (SELECT 1 FROM user_follows WHERE follower_id = ? AND followed_id = ?)",
+		followerID, followedID).Scan(&exists)
+	return exists, err
+}
+
+// UserFollowers, kullanıcıyı takip edenleri döndürür.
+func UserFollowers(userID int) ([]int, error) {
+	return userIDs("SELECT follower_id FROM user_follows WHERE followed_id = ? ORDER BY created_at", userID)
+}
+
+// FollowCounts, kullanıcının takipçi ve takip ettiği kullanıcı sayılarını döndürür.
+func FollowCounts(userID int) (followers, following int, err error) {
+	err = DB.QueryRow(`SELECT (SELECT COUNT(*) FROM user_follows WHERE followed_id = ?),
+		(SELECT COUNT(*) FROM user_follows WHERE follower_id = ?)`, userID, userID).Scan(&followers, &following)
+	return followers, following, err
+}
+
+// FollowingFeedCondition, ana sayfadaki "Following" akışı için gönderi koşuludur: takip
+// edilen kullanıcıların, takip edilen kategorilerin ve takip edilen gönderilerin kullanıcının
+// kendisine ait olmayan gönderileri. Parametre olarak kullanıcı ID'si dört kez verilir.
+const FollowingFeedCondition = `posts.user_id != ? AND (
+	posts.user_id IN (SELECT followed_id FROM user_follows WHERE follower_id = ?)
+	OR posts.id IN (SELECT post_id FROM post_follows WHERE user_id = ? AND following = 1)
+	OR EXISTS (SELECT 1 FROM json_each(posts.categories) c
+		JOIN category_follows cf ON cf.category = c.value WHERE cf.user_id = ?))`
@@ -0,0 +1,79 @@
+package datahandlers
+
+import (
+	"fmt"
+	"strings"
+
+	"form-project/markdown"
+)
+
+// Bahsetme gizlilik ayarları: kimlerin kullanıcıdan bahsederek bildirim gönderebileceği
+const (
+	MentionsEveryone = "everyone"
+	MentionsNobody   = "nobody"
+)
+
+// ValidMentionPrivacy, değerin geçerli bir bahsetme gizlilik ayarı olup olmadığını döndürür.
+func ValidMentionPrivacy(value string) bool {
+	return value == MentionsEveryone || value == MentionsNobody
+}
+
+// MentionedUser, içerikte bahsedilen ve var olan bir kullanıcıdır.
+type MentionedUser struct {
+	ID       int
+	Username string
+	Privacy  string
+}
+
+// FindMentions, içerikteki @kullanıcıadı ifadelerinden var olan kullanıcıları döndürür.
+func FindMentions(source string) ([]MentionedUser, error) {
+	names := markdown.Mentions(source)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	rows, err := DB.Query(`SELECT id, username, mention_privacy FROM users
+		WHERE username IN (?`+strings.Repeat(", ?", len(names)-1)+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []MentionedUser
+	for rows.Next() {
+		var u MentionedUser
+		if err := rows.Scan(&u.ID, &u.Username, &u.Privacy); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// RecordMentions, yeni kaydedilen gönderi veya yorumdaki (commentID 0 ise gönderi) bahsetmeleri
+// kaydeder ve bu içerikte ilk kez bahsedilen kullanıcıları döndürür. Yazarın kendisinden
+// bahsetmesi kaydedilmez. Bildirimler notifications paketi tarafından gönderilir.
+func RecordMentions(authorID int, postID, commentID int64, source string) ([]MentionedUser, error) {
+	users, err := FindMentions(source)
+	if err != nil {
+		return nil, fmt.Errorf("error finding mentions: %v", err)
+	}
+	var added []MentionedUser
+	for _, u := range users {
+		if u.ID == authorID {
+			continue
+		}
+		res, err := DB.Exec("INSERT OR IGNORE INTO mentions (user_id, author_id, post_id, comment_id) VALUES (?, ?, ?, ?)",
+			u.ID, authorID, postID, nullID(commentID))
+		if err != nil {
+			return added, fmt.Errorf("error saving mention of user %d: %v", u.ID, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			added = append(added, u)
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,551 @@
+package datahandlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// migration, şemada sırayla uygulanan tek bir değişikliği temsil eder.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// Şema değişiklikleri. Yeni değişiklikler her zaman listenin sonuna, bir sonraki sürüm numarasıyla eklenir.
+var migrations = []migration{
+	{1, "baseline columns", func(tx *sql.Tx) error {
+		// Eski veritabanlarında elle eklenmiş sütunlar, yeni kurulumlarda da bulunsun
+		columns := []struct{ table, column, definition string }{
+			{"users", "deleted", "BOOLEAN DEFAULT FALSE"},
+			{"users", "profile_picture_path", "TEXT"},
+			{"posts", "deleted", "BOOLEAN DEFAULT FALSE"},
+			{"posts", "image_path", "TEXT"},
+			{"comments", "deleted", "BOOLEAN DEFAULT FALSE"},
+			{"comments", "image_path", "TEXT"},
+		}
+		for _, c := range columns {
+			if err := addColumn(tx, c.table, c.column, c.definition); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS banned_users (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				email VARCHAR(255) NOT NULL UNIQUE
+			);
+			CREATE TABLE IF NOT EXISTS categories (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL
+			);`)
+		return err
+	}},
+	{2, "app secrets", func(tx *sql.Tx) error {
+		// Çerez imzalama gibi işlerde kullanılan, yeniden başlatmalarda korunması gereken anahtarlar
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS app_secrets (
+				name TEXT PRIMARY KEY,
+				value BLOB NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);`)
+		return err
+	}},
+	{3, "attachments", func(tx *sql.Tx) error {
+		// Yüklenen dosyaların kaydı; çöp toplayıcı hangi dosyaların hâlâ kullanıldığını buradan bulur.
+		// orphaned_at, dosyanın ilk kez sahipsiz görüldüğü zamandır (bekleme süresi buradan sayılır).
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS attachments (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				storage_key TEXT NOT NULL UNIQUE,
+				original_name TEXT,
+				content_type TEXT,
+				size INTEGER NOT NULL DEFAULT 0,
+				width INTEGER NOT NULL DEFAULT 0,
+				height INTEGER NOT NULL DEFAULT 0,
+				user_id INTEGER,
+				post_id INTEGER,
+				comment_id INTEGER,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				orphaned_at TIMESTAMP
+			);
+			CREATE INDEX IF NOT EXISTS idx_attachments_post ON attachments(post_id);
+			CREATE INDEX IF NOT EXISTS idx_attachments_comment ON attachments(comment_id);
+
+			-- Mevcut gönderi ve yorum görselleri için kayıt oluştur
+			INSERT OR IGNORE INTO attachments (storage_key, user_id, post_id, created_at)
+				SELECT image_path, user_id, id, COALESCE(created_at, CURRENT_TIMESTAMP)
+				FROM posts WHERE image_path IS NOT NULL AND image_path != '';
+			INSERT OR IGNORE INTO attachments (storage_key, user_id, comment_id, created_at)
+				SELECT image_path, user_id, id, COALESCE(created_at, CURRENT_TIMESTAMP)
+				FROM comments WHERE image_path IS NOT NULL AND image_path != '';`)
+		return err
+	}},
+	{4, "attachment order and captions", func(tx *sql.Tx) error {
+		// Gönderi ve yorumlar birden fazla eki sıralı ve açıklamalı olarak taşır
+		if err := addColumn(tx, "attachments", "position", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		if err := addColumn(tx, "attachments", "caption", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+		// 3. sürümde eski görsellerden oluşturulan kayıtların içerik türü uzantıdan doldurulur
+		_, err := tx.Exec(`
+			UPDATE attachments SET content_type = CASE lower(substr(storage_key, -4))
+				WHEN '.jpg' THEN 'image/jpeg'
+				WHEN 'jpeg' THEN 'image/jpeg'
+				WHEN '.png' THEN 'image/png'
+				WHEN '.gif' THEN 'image/gif'
+				ELSE 'application/octet-stream' END
+			WHERE content_type IS NULL OR content_type = '';`)
+		return err
+	}},
+	{5, "upload quotas", func(tx *sql.Tx) error {
+		// Kullanıcıya özel kota (bayt; NULL rolün kotası, 0 sınırsız) ve adminin rol bazında
+		// yapılandırmayı ezen kotaları. Kullanım attachments tablosundan hesaplanır.
+		if err := addColumn(tx, "users", "upload_quota", "INTEGER"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS role_quotas (
+				role TEXT PRIMARY KEY,
+				quota INTEGER NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_attachments_user ON attachments(user_id);`)
+		return err
+	}},
+	{6, "user profiles", func(tx *sql.Tx) error {
+		// Herkese açık profil bilgileri; bağlantılar satır satır saklanır
+		columns := []struct{ column, definition string }{
+			{"display_name", "TEXT NOT NULL DEFAULT ''"},
+			{"bio", "TEXT NOT NULL DEFAULT ''"},
+			{"location", "TEXT NOT NULL DEFAULT ''"},
+			{"links", "TEXT NOT NULL DEFAULT ''"},
+		}
+		for _, c := range columns {
+			if err := addColumn(tx, "users", c.column, c.definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{7, "rendered content cache", func(tx *sql.Tx) error {
+		// Markdown'dan üretilen HTML kaynağın yanında saklanır; content_version işleyici
+		// sürümünden eskiyse içerik okunurken yeniden işlenir (0: hiç işlenmemiş)
+		for _, table := range []string{"posts", "comments"} {
+			if err := addColumn(tx, table, "content_html", "TEXT NOT NULL DEFAULT ''"); err != nil {
+				return err
+			}
+			if err := addColumn(tx, table, "content_version", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{8, "mentions, notifications and blocks", func(tx *sql.Tx) error {
+		// Kullanıcının kimlerin kendisinden bahsedip bildirim gönderebileceği: everyone, nobody
+		if err := addColumn(tx, "users", "mention_privacy", "TEXT NOT NULL DEFAULT 'everyone'"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS mentions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				author_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+				comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_mentions_unique ON mentions(user_id, post_id, COALESCE(comment_id, 0));
+
+			CREATE TABLE IF NOT EXISTS notifications (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				actor_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+				type TEXT NOT NULL,
+				post_id INTEGER REFERENCES posts(id) ON DELETE CASCADE,
+				comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				read_at TIMESTAMP
+			);
+			CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, read_at);
+
+			CREATE TABLE IF NOT EXISTS user_blocks (
+				blocker_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				blocked_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (blocker_id, blocked_id)
+			);`)
+		return err
+	}},
+	{9, "notification preferences", func(tx *sql.Tx) error {
+		// message, bildirim anındaki bağlamdır (ör. silinen gönderinin başlığı, oyun türü).
+		// Tercih kaydı olmayan türler açık kabul edilir.
+		if err := addColumn(tx, "notifications", "message", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS notification_preferences (
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				type TEXT NOT NULL,
+				enabled BOOLEAN NOT NULL,
+				PRIMARY KEY (user_id, type)
+			);`)
+		return err
+	}},
+	{10, "email notifications", func(tx *sql.Tx) error {
+		// email_mode: off, immediate, daily, weekly. email_digest_at son özetin gönderildiği
+		// zamandır; emailed_at, bildirimin e-postayla gönderildiği (veya atlandığı) zamandır.
+		if err := addColumn(tx, "users", "email_mode", "TEXT NOT NULL DEFAULT 'off'"); err != nil {
+			return err
+		}
+		if err := addColumn(tx, "users", "email_digest_at", "TIMESTAMP"); err != nil {
+			return err
+		}
+		if err := addColumn(tx, "notifications", "emailed_at", "TIMESTAMP"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS email_unsubscribes (
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user_id, post_id)
+			);`)
+		return err
+	}},
+	{11, "follows", func(tx *sql.Tx) error {
+		// post_follows.following = 0 kullanıcının gönderiyi bilerek takipten çıktığını belirtir;
+		// gönderiye yeniden yorum yapmak takibi geri açmaz. Var olan gönderilerin yazarları ve
+		// yorumcuları gönderiyi takip eder.
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS post_follows (
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+				following BOOLEAN NOT NULL DEFAULT 1,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user_id, post_id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_post_follows_post ON post_follows(post_id);
+
+			CREATE TABLE IF NOT EXISTS category_follows (
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				category TEXT NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (user_id, category)
+			);
+			CREATE INDEX IF NOT EXISTS idx_category_follows_category ON category_follows(category);
+
+			CREATE TABLE IF NOT EXISTS user_follows (
+				follower_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				followed_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (follower_id, followed_id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_user_follows_followed ON user_follows(followed_id);
+
+			INSERT OR IGNORE INTO post_follows (user_id, post_id)
+				SELECT user_id, id FROM posts WHERE user_id IS NOT NULL;
+			INSERT OR IGNORE INTO post_follows (user_id, post_id)
+				SELECT DISTINCT user_id, post_id FROM comments
+				WHERE user_id IS NOT NULL AND post_id IN (SELECT id FROM posts);`)
+		return err
+	}},
+	{12, "bookmarks", func(tx *sql.Tx) error {
+		// Yer imleri yalnızca sahibine görünür; comment_id boşsa gönderinin kendisi kaydedilmiştir.
+		// Klasörü silinen yer imleri klasörsüz kalır.
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS bookmark_folders (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				name TEXT NOT NULL,
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (user_id, name)
+			);
+
+			CREATE TABLE IF NOT EXISTS bookmarks (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+				comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+				folder_id INTEGER REFERENCES bookmark_folders(id) ON DELETE SET NULL,
+				note TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_bookmarks_unique ON bookmarks(user_id, post_id, COALESCE(comment_id, 0));`)
+		return err
+	}},
+	{13, "reputation", func(tx *sql.Tx) error {
+		// Puan oy değiştikçe artımlı güncellenir; ilk değerler ve ağırlık değişiklikleri
+		// SyncReputation ile açılışta hesaplanır. app_settings hesaplamada kullanılan ağırlıkları tutar.
+		if err := addColumn(tx, "users", "reputation", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			CREATE TABLE IF NOT EXISTS app_settings (
+				name TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);`)
+		return err
+	}},
+	{14, "badges", func(tx *sql.Tx) error {
+		// Kayıt tarihi tutulmadığından mevcut kullanıcılar için ilk gönderi veya yorum tarihi kullanılır
+		if err := addColumn(tx, "users", "created_at", "TIMESTAMP"); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			UPDATE users SET created_at = COALESCE(
+				(SELECT MIN(created_at) FROM (
+					SELECT created_at FROM posts WHERE user_id = users.id
+					UNION ALL
+					SELECT created_at FROM comments WHERE user_id = users.id)),
+				CURRENT_TIMESTAMP)
+			WHERE created_at IS NULL;
+
+			CREATE TABLE IF NOT EXISTS user_badges (
+				user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				badge TEXT NOT NULL,
+				awarded_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (user_id, badge)
+			);`)
+		return err
+	}},
+	{15, "questions", func(tx *sql.Tx) error {
+		// Soru olarak işaretlenen gönderilerde bir yorum kabul edilmiş cevap olabilir
+		if err := addColumn(tx, "posts", "is_question", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+		return addColumn(tx, "posts", "accepted_comment_id", "INTEGER REFERENCES comments(id) ON DELETE SET NULL")
+	}},
+	{16, "ranking scores", func(tx *sql.Tx) error {
+		// Sıralamalar her istekte oyları toplamak yerine oy ve yorumlarda güncellenen puanları kullanır
+		columns := []struct{ name, definition string }{
+			{"score", "INTEGER NOT NULL DEFAULT 0"},
+			{"hot_score", "REAL NOT NULL DEFAULT 0"},
+			{"controversy_score", "REAL NOT NULL DEFAULT 0"},
+		}
+		for _, c := range columns {
+			if err := addColumn(tx, "posts", c.name, c.definition); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_posts_hot ON posts(hot_score);
+			CREATE INDEX IF NOT EXISTS idx_posts_controversy ON posts(controversy_score);
+			CREATE INDEX IF NOT EXISTS idx_posts_score ON posts(score);`)
+		// Puanlar sayaçlardan hesaplandığından ilk değerler 17. değişiklikte yazılır
+		return err
+	}},
+	{17, "counters", func(tx *sql.Tx) error {
+		// Listelemeler oyları ve yorumları saymak yerine oy ve yorum yazılırken güncellenen sayaçları okur
+		columns := []struct{ table, column string }{
+			{"posts", "like_count"},
+			{"posts", "dislike_count"},
+			{"posts", "comment_count"},
+			{"comments", "like_count"},
+			{"comments", "dislike_count"},
+		}
+		for _, c := range columns {
+			if err := addColumn(tx, c.table, c.column, "INTEGER NOT NULL DEFAULT 0"); err != nil {
+				return err
+			}
+		}
+		if _, err := reconcileCounters(tx); err != nil {
+			return err
+		}
+		return refreshPostScores(tx, "1 = 1")
+	}},
+	{18, "unique votes", func(tx *sql.Tx) error {
+		// Bir kullanıcının bir gönderi veya yorumda tek oyu olur. Eş zamanlı tıklamalardan kalmış
+		// yinelenen oylardan en sonuncusu tutulur; sayaçlar yeniden hesaplanır ve puanların açılışta
+		// yeniden hesaplanması için kayıtlı ağırlıklar silinir.
+		_, err := tx.Exec(`
+			DELETE FROM votes WHERE comment_id IS NULL AND id NOT IN
+				(SELECT MAX(id) FROM votes WHERE comment_id IS NULL GROUP BY user_id, post_id);
+			DELETE FROM votes WHERE comment_id IS NOT NULL AND id NOT IN
+				(SELECT MAX(id) FROM votes WHERE comment_id IS NOT NULL GROUP BY user_id, comment_id);
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_votes_user_post ON votes(user_id, post_id) WHERE comment_id IS NULL;
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_votes_user_comment ON votes(user_id, comment_id) WHERE comment_id IS NOT NULL;
+			DELETE FROM app_settings WHERE name = 'reputation_weights';`)
+		if err != nil {
+			return err
+		}
+		if _, err := reconcileCounters(tx); err != nil {
+			return err
+		}
+		return refreshPostScores(tx, "1 = 1")
+	}},
+	{19, "foreign keys", func(tx *sql.Tx) error {
+		// Yabancı anahtarlar artık açık (Open). Kısıtlar kapalıyken silinen kullanıcı, gönderi ve
+		// yorumlardan kalan satırlar, ON DELETE kuralları uygulanmış gibi temizlenir; Migrate
+		// ardından hiçbir satırın eksik bir kayda başvurmadığını doğrular.
+		orphans := []struct {
+			table, column, parent string
+			setNull               bool
+		}{
+			{"sessions", "user_id", "users", false},
+			{"reports", "user_id", "users", false},
+			{"reports", "post_id", "posts", false},
+			{"mentions", "user_id", "users", false},
+			{"mentions", "author_id", "users", false},
+			{"mentions", "post_id", "posts", false},
+			{"mentions", "comment_id", "comments", false},
+			{"notifications", "user_id", "users", false},
+			{"notifications", "actor_id", "users", false},
+			{"notifications", "post_id", "posts", false},
+			{"notifications", "comment_id", "comments", false},
+			{"user_blocks", "blocker_id", "users", false},
+			{"user_blocks", "blocked_id", "users", false},
+			{"notification_preferences", "user_id", "users", false},
+			{"email_unsubscribes", "user_id", "users", false},
+			{"email_unsubscribes", "post_id", "posts", false},
+			{"post_follows", "user_id", "users", false},
+			{"post_follows", "post_id", "posts", false},
+			{"category_follows", "user_id", "users", false},
+			{"user_follows", "follower_id", "users", false},
+			{"user_follows", "followed_id", "users", false},
+			{"bookmark_folders", "user_id", "users", false},
+			{"bookmarks", "user_id", "users", false},
+			{"bookmarks", "post_id", "posts", false},
+			{"bookmarks", "comment_id", "comments", false},
+			{"bookmarks", "folder_id", "bookmark_folders", true},
+			{"user_badges", "user_id", "users", false},
+			{"posts", "accepted_comment_id", "comments", true},
+		}
+		for _, o := range orphans {
+			query := "DELETE FROM " + o.table
+			if o.setNull {
+				query = "UPDATE " + o.table + " SET " + o.column + " = NULL"
+			}
+			query += " WHERE " + o.column + " IS NOT NULL AND " + o.column + " NOT IN (SELECT id FROM " + o.parent + ")"
+			if _, err := tx.Exec(query); err != nil {
+				return fmt.Errorf("%s.%s: %v", o.table, o.column, err)
+			}
+		}
+		return nil
+	}},
+}
+
+// Bekleyen tüm şema değişikliklerini sırayla uygular. Geçişler, eski verilerden türetilen
+// satırlar ara adımlarda kısıtlara takılmasın diye yabancı anahtarlar kapalı tek bir bağlantıda
+// çalışır; sonunda tüm kısıtların sağlandığı doğrulanır.
+func Migrate() error {
+	_, err := DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations table: %v", err)
+	}
+
+	current, err := SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current >= migrations[len(migrations)-1].version {
+		return nil
+	}
+
+	ctx := context.Background()
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// PRAGMA foreign_keys işlem içinde değiştirilemez
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := m.up(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s) failed: %v", m.version, m.name, err)
+		}
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.version, m.name); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		log.Printf("Applied migration %d: %s", m.version, m.name)
+	}
+	return checkForeignKeys(ctx, conn)
+}
+
+// Eksik bir kayda başvuran ilk satırı hata olarak döndürür.
+func checkForeignKeys(ctx context.Context, conn *sql.Conn) error {
+	var table, parent string
+	var rowID sql.NullInt64
+	var fk int
+	err := conn.QueryRowContext(ctx, "PRAGMA foreign_key_check").Scan(&table, &rowID, &parent, &fk)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("foreign key check: %s row %d references a missing %s row", table, rowID.Int64, parent)
+}
+
+// Veritabanına uygulanmış en son şema sürümünü döndürür.
+func SchemaVersion() (int, error) {
+	var version int
+	err := DB.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("error reading schema version: %v", err)
+	}
+	return version, nil
+}
+
+// Kodda tanımlı en son şema sürümünü döndürür.
+func LatestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].version
+}
+
+// Tabloda sütun yoksa ekler; SQLite "ADD COLUMN IF NOT EXISTS" desteklemez.
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	exists := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	if exists {
+		return nil
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
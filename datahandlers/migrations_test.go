@@ -0,0 +1,117 @@
+package datahandlers
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Tüm tabloları oluşturup geçişleri yabancı anahtarlar açıkken uygular
+func openMigratedDB(t *testing.T) {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "forum.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := DB
+	DB = db
+	t.Cleanup(func() {
+		DB = old
+		db.Close()
+	})
+	CreateTables()
+	if err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestForeignKeysCascade(t *testing.T) {
+	openMigratedDB(t)
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := DB.Exec("INSERT INTO users (email, username, password) VALUES (?, ?, 'x')", name+"@example.com", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := DB.Exec("INSERT INTO posts (id, user_id, title) VALUES (1, 1, 'x')"); err != nil {
+		t.Fatal(err)
+	}
+	if err := FollowUser(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := BlockUser(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := FollowPost(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateNotification(Notification{UserID: 2, ActorID: 1, Type: NotificationComment, PostID: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Olmayan kayıtlara başvuran satırlar eklenemez
+	if err := FollowUser(1, 99); err == nil {
+		t.Error("FollowUser with a missing user succeeded")
+	}
+
+	if _, err := DB.Exec("DELETE FROM users WHERE id = 2"); err != nil {
+		t.Fatal(err)
+	}
+	if followers, _, err := FollowCounts(1); err != nil || followers != 0 {
+		t.Errorf("FollowCounts after deleting the follower = %d, %v; want 0", followers, err)
+	}
+	for _, table := range []string{"user_blocks", "post_follows", "notifications"} {
+		var n int
+		if err := DB.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil || n != 0 {
+			t.Errorf("%s rows after deleting the user = %d, %v; want 0", table, n, err)
+		}
+	}
+}
+
+func TestForeignKeyMigrationRemovesOrphans(t *testing.T) {
+	openMigratedDB(t)
+	// Kısıtlar kapalıyken kalmış satırlar
+	_, err := DB.Exec(`PRAGMA foreign_keys = OFF;
+		INSERT INTO users (id, email, username, password) VALUES (1, 'a@example.com', 'alice', 'x');
+		INSERT INTO posts (id, user_id, title, accepted_comment_id) VALUES (1, 1, 'x', 42);
+		INSERT INTO user_follows (follower_id, followed_id) VALUES (9, 1), (1, 9);
+		INSERT INTO bookmarks (user_id, post_id, folder_id) VALUES (1, 1, 7), (1, 8, NULL);
+		INSERT INTO reports (post_id, user_id) VALUES (8, 1);
+		DELETE FROM schema_migrations WHERE version = 19;
+		PRAGMA foreign_keys = ON;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int{
+		"SELECT COUNT(*) FROM user_follows":                            0,
+		"SELECT COUNT(*) FROM reports":                                 0,
+		"SELECT COUNT(*) FROM bookmarks":                               1,
+		"SELECT COUNT(*) FROM bookmarks WHERE folder_id IS NULL":       1,
+		"SELECT COUNT(*) FROM posts WHERE accepted_comment_id IS NULL": 1,
+	}
+	for query, want := range counts {
+		var n int
+		if err := DB.QueryRow(query).Scan(&n); err != nil || n != want {
+			t.Errorf("%s = %d, %v; want %d", query, n, err, want)
+		}
+	}
+}
+
+func TestCreateNotificationSkipsDeletedUsers(t *testing.T) {
+	openMigratedDB(t)
+	if _, err := DB.Exec("INSERT INTO users (id, email, username, password) VALUES (1, 'a@example.com', 'alice', 'x')"); err != nil {
+		t.Fatal(err)
+	}
+	for _, userID := range []int{1, 2} {
+		if err := CreateNotification(Notification{UserID: userID, Type: NotificationPostRemoved, Message: "x"}); err != nil {
+			t.Errorf("CreateNotification for user %d: %v", userID, err)
+		}
+	}
+	var n int
+	DB.QueryRow("SELECT COUNT(*) FROM notifications").Scan(&n)
+	if n != 1 {
+		t.Errorf("notifications = %d, want 1", n)
+	}
+}
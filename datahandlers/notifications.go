@@ -0,0 +1,237 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Bildirim türleri
+const (
+	NotificationMention          = "mention"           // Kullanıcıdan bir gönderi veya yorumda bahsedildi
+	NotificationComment          = "comment"           // Kullanıcının gönderisine yorum yapıldı
+	NotificationReply            = "reply"             // Kullanıcının takip ettiği gönderiye yeni yorum yapıldı
+	NotificationVote             = "vote"              // Kullanıcının gönderisi veya yorumu oylandı (Message: like, dislike)
+	NotificationPostRemoved      = "post_removed"      // Gönderi bir moderatör tarafından silindi (Message: başlık)
+	NotificationReportResolved   = "report_resolved"   // Bildirilen gönderi hakkında işlem yapıldı (Message: başlık)
+	NotificationFollowedUser     = "followed_user"     // Takip edilen kullanıcı yeni bir gönderi paylaştı
+	NotificationFollowedCategory = "followed_category" // Takip edilen kategoride yeni gönderi (Message: kategori)
+	NotificationAcceptedAnswer   = "accepted_answer"   // Kullanıcının yorumu sorunun cevabı olarak kabul edildi
+)
+
+// Notification, bir kullanıcıya gönderilen bildirimdir. ActorID bildirime neden olan
+// kullanıcıdır; PostID ve CommentID bildirimin ilgili olduğu içeriktir (yoksa 0).
+type Notification struct {
+	ID        int64
+	UserID    int
+	ActorID   int
+	Type      string
+	PostID    int64
+	CommentID int64
+	Message   string
+	CreatedAt time.Time
+	ReadAt    sql.NullTime
+
+	// Listelenirken doldurulur
+	ActorUsername string
+	ActorAvatar   string
+	PostTitle     string // Gönderi silinmişse Message
+	postDeleted   bool
+}
+
+// Unread, bildirimin henüz okunmadığını belirtir.
+func (n Notification) Unread() bool {
+	return !n.ReadAt.Valid
+}
+
+// Moderation, bildirimin bir moderatör işlemiyle ilgili olduğunu belirtir; işlemi yapan gösterilmez.
+func (n Notification) Moderation() bool {
+	return n.Type == NotificationPostRemoved || n.Type == NotificationReportResolved
+}
+
+// Verb, bildirimde işlemi yapan kullanıcının ardından gösterilecek ifadedir.
+func (n Notification) Verb() string {
+	target := "post"
+	if n.CommentID != 0 {
+		target = "comment on"
+	}
+	switch n.Type {
+	case NotificationMention:
+		return "mentioned you in"
+	case NotificationComment:
+		return "commented on your post"
+	case NotificationReply:
+		return "commented on"
+	case NotificationVote:
+		if n.Message == "dislike" {
+			return "disliked your " + target
+		}
+		return "liked your " + target
+	case NotificationPostRemoved:
+		return "removed your post"
+	case NotificationReportResolved:
+		return "acted on your report about"
+	case NotificationFollowedUser:
+		return "published a new post"
+	case NotificationFollowedCategory:
+		return "posted in " + n.Message
+	case NotificationAcceptedAnswer:
+		return "accepted your answer on"
+	}
+	return n.Type
+}
+
+// URL, bildirimin yönlendirdiği adrestir; gönderi artık yoksa boştur.
+func (n Notification) URL() string {
+	if n.PostID == 0 || n.postDeleted {
+		return ""
+	}
+	if n.CommentID != 0 {
+		return fmt.Sprintf("/viewPost?id=%d#comment-%d", n.PostID, n.CommentID)
+	}
+	return fmt.Sprintf("/viewPost?id=%d", n.PostID)
+}
+
+// CreateNotification, yeni ve okunmamış bir bildirim kaydeder. Hesabı silinmiş kullanıcılara
+// (ör. silinen hesabın kalan gönderileri için) bildirim kaydedilmez.
+func CreateNotification(n Notification) error {
+	_, err := DB.Exec(`INSERT INTO notifications (user_id, actor_id, type, post_id, comment_id, message)
+		SELECT ?, ?, ?, ?, ?, ? WHERE EXISTS (SELECT 1 FROM users WHERE id = ?)`,
+		n.UserID, nullID(int64(n.ActorID)), n.Type, nullID(n.PostID), nullID(n.CommentID), n.Message, n.UserID)
+	if err != nil {
+		return fmt.Errorf("error creating %s notification for user %d: %v", n.Type, n.UserID, err)
+	}
+	return nil
+}
+
+// HasUnreadNotification, aynı kişiden aynı içerik için aynı türde ve aynı mesajla okunmamış bir bildirim
+// olup olmadığını döndürür; tekrarlanan işlemler (ör. oyu geri alıp yeniden vermek) yeni
+// bildirim oluşturmaz.
+func HasUnreadNotification(n Notification) (bool, error) {
+	var exists bool
+	err := DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM notifications WHERE user_id = ? AND type = ? AND read_at IS NULL
+		AND COALESCE(actor_id, 0) = ? AND COALESCE(post_id, 0) = ? AND COALESCE(comment_id, 0) = ? AND message = ?)`,
+		n.UserID, n.Type, n.ActorID, n.PostID, n.CommentID, n.Message).Scan(&exists)
+	return exists, err
+}
+
+// UserNotifications, kullanıcının en yeni bildirimlerini döndürür.
+func UserNotifications(userID, limit int) ([]Notification, error) {
+	return queryNotifications("n.user_id = ? ORDER BY n.created_at DESC, n.id DESC LIMIT ?", userID, limit)
+}
+
+// Bildirimleri işlemi yapan kullanıcı ve gönderi bilgileriyle birlikte listeler; where
+// sıralama ve sınır da içerebilir.
+func queryNotifications(where string, args ...interface{}) ([]Notification, error) {
+	rows, err := DB.Query(`SELECT n.id, n.user_id, COALESCE(n.actor_id, 0), n.type, COALESCE(n.post_id, 0),
+			COALESCE(n.comment_id, 0), n.message, n.created_at, n.read_at,
+			COALESCE(u.username, ''), COALESCE(u.profile_picture_path, ''), COALESCE(p.title, n.message), p.id IS NULL OR p.deleted = 1
+		FROM notifications n
+		LEFT JOIN users u ON u.id = n.actor_id
+		LEFT JOIN posts p ON p.id = n.post_id
+		WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []Notification
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.ActorID, &n.Type, &n.PostID, &n.CommentID, &n.Message,
+			&n.CreatedAt, &n.ReadAt, &n.ActorUsername, &n.ActorAvatar, &n.PostTitle, &n.postDeleted); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// UnreadNotificationCount, kullanıcının okunmamış bildirim sayısını döndürür.
+func UnreadNotificationCount(userID int) (int, error) {
+	var count int
+	err := DB.QueryRow("SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL", userID).Scan(&count)
+	return count, err
+}
+
+// MarkNotificationRead, kullanıcının bildirimini okundu olarak işaretler.
+func MarkNotificationRead(userID int, id int64) error {
+	_, err := DB.Exec("UPDATE notifications SET read_at = ? WHERE id = ? AND user_id = ? AND read_at IS NULL",
+		time.Now(), id, userID)
+	return err
+}
+
+// MarkAllNotificationsRead, kullanıcının tüm bildirimlerini okundu olarak işaretler.
+func MarkAllNotificationsRead(userID int) error {
+	_, err := DB.Exec("UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL", time.Now(), userID)
+	return err
+}
+
+// NotificationPreferences, kullanıcının kapattığı veya açtığı tercihleri döndürür; listede
+// olmayan tercihler açıktır.
+func NotificationPreferences(userID int) (map[string]bool, error) {
+	rows, err := DB.Query("SELECT type, enabled FROM notification_preferences WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := make(map[string]bool)
+	for rows.Next() {
+		var key string
+		var enabled bool
+		if err := rows.Scan(&key, &enabled); err != nil {
+			return nil, err
+		}
+		prefs[key] = enabled
+	}
+	return prefs, rows.Err()
+}
+
+// NotificationEnabled, kullanıcının verilen tercih için bildirim almak isteyip istemediğini döndürür.
+func NotificationEnabled(userID int, key string) (bool, error) {
+	var enabled bool
+	err := DB.QueryRow("SELECT enabled FROM notification_preferences WHERE user_id = ? AND type = ?", userID, key).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+	return enabled, err
+}
+
+// SetNotificationPreference, kullanıcının bir tercihini açar veya kapatır.
+func SetNotificationPreference(userID int, key string, enabled bool) error {
+	_, err := DB.Exec(`INSERT INTO notification_preferences (user_id, type, enabled) VALUES (?, ?, ?)
+		ON CONFLICT (user_id, type) DO UPDATE SET enabled = excluded.enabled`, userID, key, enabled)
+	return err
+}
+
+// PostReporters, gönderiyi bildirmiş farklı kullanıcıları döndürür.
+func PostReporters(postID int64) ([]int, error) {
+	return userIDs("SELECT DISTINCT user_id FROM reports WHERE post_id = ?", postID)
+}
+
+func userIDs(query string, args ...interface{}) ([]int, error) {
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Sıfır ID'yi NULL olarak yazar.
+func nullID(id int64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
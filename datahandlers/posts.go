@@ -0,0 +1,59 @@
+package datahandlers
+
+import "time"
+
+// NewPost, CreatePost ile eklenecek gönderidir. Categories JSON dizisi olarak saklanır.
+type NewPost struct {
+	UserID      int
+	Title       string
+	Content     string
+	Categories  string
+	ImagePath   string
+	IsQuestion  bool
+	Attachments []AttachmentLink // Gönderiye sırasıyla bağlanacak ekler
+}
+
+// CreatePost, gönderiyi ekler ve eklerini aynı işlemde bağlar; ekler bağlanamazsa gönderi
+// de kaydedilmez. Gönderinin ID'si döner.
+func CreatePost(p NewPost) (int64, error) {
+	contentHTML, contentVersion := RenderedContent(p.Content)
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO posts (user_id, title, content, content_html, content_version, categories, created_at, image_path, is_question)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.UserID, p.Title, p.Content, contentHTML, contentVersion, p.Categories, time.Now(), p.ImagePath, p.IsQuestion)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := linkAttachments(tx, "post_id", id, p.Attachments); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// DeletePost, gönderiyi kalıcı olarak siler. Bahsetmeler, bildirimler, takipler ve yer imleri
+// yabancı anahtarlarla birlikte silinir; kuralı olmayan şikayetler aynı işlemde silinir.
+func DeletePost(postID int64) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM reports WHERE post_id = ?", postID); err != nil {
+		return err
+	}
+	res, err := tx.Exec("DELETE FROM posts WHERE id = ?", postID)
+	if err := affectedOne(res, err); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
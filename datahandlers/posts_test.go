@@ -0,0 +1,50 @@
+package datahandlers
+
+import "testing"
+
+func TestCreatePostLinksAttachmentsAtomically(t *testing.T) {
+	openTestDB(t, `
+		CREATE TABLE users (id INTEGER PRIMARY KEY, username TEXT, mention_privacy TEXT DEFAULT 'everyone');
+		CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER, title TEXT, content TEXT, content_html TEXT,
+			content_version INTEGER, categories TEXT, created_at TIMESTAMP, image_path TEXT, is_question BOOLEAN);
+		CREATE TABLE attachments (id INTEGER PRIMARY KEY, post_id INTEGER, comment_id INTEGER, position INTEGER,
+			caption TEXT, orphaned_at TIMESTAMP);
+		INSERT INTO attachments (id, orphaned_at) VALUES (1, CURRENT_TIMESTAMP), (2, NULL);`)
+
+	// Bağlanamayan bir ek gönderiyle birlikte geri alınır
+	if _, err := CreatePost(NewPost{UserID: 1, Title: "x", Attachments: []AttachmentLink{{ID: 1}, {ID: 99}}}); err == nil {
+		t.Fatal("CreatePost with a missing attachment succeeded")
+	}
+	var posts, linked int
+	DB.QueryRow("SELECT COUNT(*) FROM posts").Scan(&posts)
+	DB.QueryRow("SELECT COUNT(*) FROM attachments WHERE post_id IS NOT NULL").Scan(&linked)
+	if posts != 0 || linked != 0 {
+		t.Errorf("after failed CreatePost: %d posts, %d linked attachments, want 0 and 0", posts, linked)
+	}
+
+	id, err := CreatePost(NewPost{UserID: 1, Title: "x", Attachments: []AttachmentLink{{ID: 2, Caption: "b"}, {ID: 1, Caption: "a"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := DB.Query("SELECT id, position, caption, orphaned_at IS NULL FROM attachments WHERE post_id = ? ORDER BY position", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []AttachmentLink
+	for rows.Next() {
+		var link AttachmentLink
+		var position int
+		var unorphaned bool
+		if err := rows.Scan(&link.ID, &position, &link.Caption, &unorphaned); err != nil {
+			t.Fatal(err)
+		}
+		if position != len(got) || !unorphaned {
+			t.Errorf("attachment %d: position %d, unorphaned %v", link.ID, position, unorphaned)
+		}
+		got = append(got, link)
+	}
+	if len(got) != 2 || got[0] != (AttachmentLink{ID: 2, Caption: "b"}) || got[1] != (AttachmentLink{ID: 1, Caption: "a"}) {
+		t.Errorf("linked attachments = %v", got)
+	}
+}
@@ -0,0 +1,117 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// Profile, bir kullanıcının herkese açık profil bilgileridir.
+type Profile struct {
+	ID             int
+	Username       string
+	Role           string
+	DisplayName    string
+	Bio            string
+	Location       string
+	Links          []string
+	AvatarPath     string // Depodaki profil fotoğrafı anahtarı; yoksa boş
+	MentionPrivacy string // Kimlerin bahsederek bildirim gönderebileceği: MentionsEveryone, MentionsNobody
+}
+
+// Name, profilde gösterilecek addır: görünen ad, yoksa kullanıcı adı.
+func (p Profile) Name() string {
+	if p.DisplayName != "" {
+		return p.DisplayName
+	}
+	return p.Username
+}
+
+const profileColumns = `id, COALESCE(username, ''), role, display_name, bio, location, links, COALESCE(profile_picture_path, ''), mention_privacy`
+
+func scanProfile(row *sql.Row) (*Profile, error) {
+	var p Profile
+	var links string
+	if err := row.Scan(&p.ID, &p.Username, &p.Role, &p.DisplayName, &p.Bio, &p.Location, &links, &p.AvatarPath, &p.MentionPrivacy); err != nil {
+		return nil, err
+	}
+	if links != "" {
+		p.Links = strings.Split(links, "\n")
+	}
+	return &p, nil
+}
+
+// ProfileByUsername, kullanıcı adına göre profili döndürür; bulunamazsa sql.ErrNoRows döner.
+func ProfileByUsername(username string) (*Profile, error) {
+	return scanProfile(DB.QueryRow("SELECT "+profileColumns+" FROM users WHERE username = ?", username))
+}
+
+// ProfileByID, kullanıcı ID'sine göre profili döndürür; bulunamazsa sql.ErrNoRows döner.
+func ProfileByID(userID int) (*Profile, error) {
+	return scanProfile(DB.QueryRow("SELECT "+profileColumns+" FROM users WHERE id = ?", userID))
+}
+
+// UpdateProfile, profilin metin alanlarını ve bahsetme ayarını günceller. Değerler çağıran
+// tarafından doğrulanmış olmalıdır.
+func UpdateProfile(p Profile) error {
+	_, err := DB.Exec("UPDATE users SET display_name = ?, bio = ?, location = ?, links = ?, mention_privacy = ? WHERE id = ?",
+		p.DisplayName, p.Bio, p.Location, strings.Join(p.Links, "\n"), p.MentionPrivacy, p.ID)
+	return err
+}
+
+// SetAvatar, kullanıcının profil fotoğrafını ayarlar; boş anahtar fotoğrafı kaldırır.
+// Eski fotoğraf artık kullanılmadığı için çöp toplayıcı tarafından silinir.
+func SetAvatar(userID int, key string) error {
+	var path interface{}
+	if key != "" {
+		path = key
+	}
+	_, err := DB.Exec("UPDATE users SET profile_picture_path = ? WHERE id = ?", path, userID)
+	return err
+}
+
+// UserComment, profil sayfasında listelenen bir yorumdur.
+type UserComment struct {
+	ID          int
+	PostID      int
+	PostTitle   string
+	Content     string
+	ContentHTML template.HTML
+	CreatedAt   time.Time
+
+	cachedHTML    string // Önbellekteki HTML ve sürümü
+	cachedVersion int
+}
+
+// UserComments, kullanıcının silinmemiş gönderilerdeki son yorumlarını en yeniden eskiye döndürür.
+func UserComments(userID, limit int) ([]UserComment, error) {
+	rows, err := DB.Query(`SELECT c.id, c.post_id, p.title, c.content, c.content_html, c.content_version, c.created_at FROM comments c
+		JOIN posts p ON p.id = c.post_id
+		WHERE c.user_id = ? AND c.deleted = 0 AND p.deleted = 0
+		ORDER BY c.created_at DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []UserComment
+	for rows.Next() {
+		var c UserComment
+		if err := rows.Scan(&c.ID, &c.PostID, &c.PostTitle, &c.Content, &c.cachedHTML, &c.cachedVersion, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Eski önbellekler güncellenirken okuma kilidi tutulmasın diye sorgu önce kapatılır
+	rows.Close()
+
+	for i := range comments {
+		c := &comments[i]
+		c.ContentHTML = CommentHTML(c.ID, c.Content, c.cachedHTML, c.cachedVersion)
+	}
+	return comments, nil
+}
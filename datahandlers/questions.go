@@ -0,0 +1,58 @@
+package datahandlers
+
+import (
+	"database/sql"
+)
+
+// Question, bir gönderinin soru/cevap durumudur.
+type Question struct {
+	PostID            int64
+	AuthorID          int
+	IsQuestion        bool
+	AcceptedCommentID int64 // Kabul edilmiş cevap yoksa 0
+}
+
+// PostQuestion, silinmemiş gönderinin soru durumunu döndürür; gönderi yoksa sql.ErrNoRows döner.
+func PostQuestion(postID int64) (Question, error) {
+	q := Question{PostID: postID}
+	var accepted sql.NullInt64
+	err := DB.QueryRow("SELECT user_id, is_question, accepted_comment_id FROM posts WHERE id = ? AND deleted = 0", postID).
+		Scan(&q.AuthorID, &q.IsQuestion, &accepted)
+	q.AcceptedCommentID = accepted.Int64
+	return q, err
+}
+
+// SetQuestion, gönderiyi soru olarak işaretler ya da işareti kaldırır. İşaret kaldırılınca kabul
+// edilmiş cevap da kaldırılır.
+func SetQuestion(postID int64, question bool) error {
+	_, err := DB.Exec("UPDATE posts SET is_question = ?, accepted_comment_id = CASE WHEN ? THEN accepted_comment_id END WHERE id = ?",
+		question, question, postID)
+	return err
+}
+
+// AnswerAuthor, gönderideki silinmemiş yorumun yazarını döndürür; yorum gönderiye ait değilse sql.ErrNoRows döner.
+func AnswerAuthor(postID, commentID int64) (int, error) {
+	var authorID int
+	err := DB.QueryRow("SELECT user_id FROM comments WHERE id = ? AND post_id = ? AND deleted = 0", commentID, postID).Scan(&authorID)
+	return authorID, err
+}
+
+// SetAcceptedAnswer, gönderinin kabul edilmiş cevabını ayarlar; commentID 0 ise kaldırır.
+func SetAcceptedAnswer(postID, commentID int64) error {
+	_, err := DB.Exec("UPDATE posts SET accepted_comment_id = ? WHERE id = ?", nullID(commentID), postID)
+	return err
+}
+
+// ClearAcceptedAnswer, silinen yorum bir gönderinin kabul edilmiş cevabıysa işareti kaldırır.
+func ClearAcceptedAnswer(commentID int64) error {
+	_, err := DB.Exec("UPDATE posts SET accepted_comment_id = NULL WHERE accepted_comment_id = ?", commentID)
+	return err
+}
+
+// AcceptedAnswers, kullanıcının başkalarının sorularında kabul edilmiş cevaplarının sayısıdır.
+func AcceptedAnswers(userID int) (int, error) {
+	var count int
+	err := DB.QueryRow(`SELECT COUNT(*) FROM posts p JOIN comments c ON c.id = p.accepted_comment_id
+		WHERE c.user_id = ? AND p.user_id != c.user_id AND p.deleted = 0 AND c.deleted = 0`, userID).Scan(&count)
+	return count, err
+}
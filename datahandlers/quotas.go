@@ -0,0 +1,76 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// UploadUsage, kullanıcının yüklediği eklerin toplam boyutunu döndürür. Sahipsiz kalan
+// ekler çöp toplayıcı silene kadar kullanıma dahildir.
+func UploadUsage(userID int) (int64, error) {
+	var used int64
+	err := DB.QueryRow("SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = ?", userID).Scan(&used)
+	if err != nil {
+		return 0, fmt.Errorf("error reading upload usage of user %d: %v", userID, err)
+	}
+	return used, nil
+}
+
+// UploadQuota, kullanıcının rolünü ve varsa kullanıcıya ve rolüne tanımlanmış kotaları döndürür.
+// Tanımlı olmayan kotalar Valid=false döner; bu durumda yapılandırmadaki değer geçerlidir.
+func UploadQuota(userID int) (role string, userQuota, roleQuota sql.NullInt64, err error) {
+	err = DB.QueryRow(`SELECT u.role, u.upload_quota, rq.quota FROM users u
+		LEFT JOIN role_quotas rq ON rq.role = u.role WHERE u.id = ?`, userID).Scan(&role, &userQuota, &roleQuota)
+	return role, userQuota, roleQuota, err
+}
+
+// SetUserQuota, kullanıcıya özel kotayı bayt cinsinden ayarlar; Valid=false rolün kotasına döndürür.
+func SetUserQuota(userID int, quota sql.NullInt64) error {
+	_, err := DB.Exec("UPDATE users SET upload_quota = ? WHERE id = ?", quota, userID)
+	return err
+}
+
+// RoleQuotas, adminin rol bazında tanımladığı kotaları döndürür.
+func RoleQuotas() (map[string]int64, error) {
+	rows, err := DB.Query("SELECT role, quota FROM role_quotas")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quotas := make(map[string]int64)
+	for rows.Next() {
+		var role string
+		var quota int64
+		if err := rows.Scan(&role, &quota); err != nil {
+			return nil, err
+		}
+		quotas[role] = quota
+	}
+	return quotas, rows.Err()
+}
+
+// SetRoleQuota, rolün kotasını bayt cinsinden ayarlar; Valid=false yapılandırmadaki değere döndürür.
+func SetRoleQuota(role string, quota sql.NullInt64) error {
+	if !quota.Valid {
+		_, err := DB.Exec("DELETE FROM role_quotas WHERE role = ?", role)
+		return err
+	}
+	_, err := DB.Exec(`INSERT INTO role_quotas (role, quota) VALUES (?, ?)
+		ON CONFLICT(role) DO UPDATE SET quota = excluded.quota`, role, quota.Int64)
+	return err
+}
+
+// FormatSize, bayt cinsinden boyutu okunabilir biçimde döndürür (ör. "1.2 MB").
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
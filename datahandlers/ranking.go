@@ -0,0 +1,87 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"math"
+	"time"
+)
+
+// "Hot" sıralamasında zaman etkisi: her hotDecay saniyelik yaşlanma puanda 10 kat farka denk gelir.
+const (
+	hotEpoch = 1704067200 // 2024-01-01 00:00 UTC
+	hotDecay = 45000      // 12,5 saat
+)
+
+// HotScore, oylara ve yorumlara göre zamanla azalan sıralama puanıdır. Net oy (beğeni -
+// beğenmeme) ve yorum sayısının yarısı logaritmik olarak sayılır; yeni gönderiler eskilerin
+// önüne geçer. Puan yalnızca oy ve yorum değiştiğinde yeniden hesaplanır.
+func HotScore(likes, dislikes, comments int, createdAt time.Time) float64 {
+	s := float64(likes-dislikes) + float64(comments)/2
+	order := math.Log10(math.Max(math.Abs(s), 1))
+	sign := 0.0
+	if s > 0 {
+		sign = 1
+	} else if s < 0 {
+		sign = -1
+	}
+	return sign*order + float64(createdAt.Unix()-hotEpoch)/hotDecay
+}
+
+// ControversyScore, beğeni ve beğenmemeleri hem çok hem de dengeli olan gönderilerde yüksektir.
+func ControversyScore(likes, dislikes int) float64 {
+	if likes <= 0 || dislikes <= 0 {
+		return 0
+	}
+	magnitude := float64(likes + dislikes)
+	balance := float64(min(likes, dislikes)) / float64(max(likes, dislikes))
+	return math.Pow(magnitude, balance)
+}
+
+// Sorgu ve güncellemeyi hem DB hem de göç işlemlerinde (tx) çalıştırmak için
+type queryExecer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// RefreshPostScores, gönderinin net oy, "hot" ve "controversial" puanlarını oy ve yorum
+// sayaçlarından yeniden hesaplar. Gönderiye oy verildiğinde ve yorum eklenip silindiğinde çağrılır.
+func RefreshPostScores(postID int64) error {
+	return refreshPostScores(DB, "posts.id = ?", postID)
+}
+
+func refreshPostScores(db queryExecer, where string, args ...interface{}) error {
+	rows, err := db.Query("SELECT id, created_at, like_count, dislike_count, comment_count FROM posts WHERE "+where, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type scores struct {
+		id               int64
+		score            int
+		hot, controversy float64
+	}
+	var updates []scores
+	for rows.Next() {
+		var id int64
+		var createdAt sql.NullTime
+		var likes, dislikes, comments int
+		if err := rows.Scan(&id, &createdAt, &likes, &dislikes, &comments); err != nil {
+			return err
+		}
+		updates = append(updates, scores{id, likes - dislikes, HotScore(likes, dislikes, comments, createdAt.Time), ControversyScore(likes, dislikes)})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	// Yazmadan önce okuma kapatılır (rollback journal kipinde okuma kilidi yazmayı engeller)
+	rows.Close()
+
+	for _, u := range updates {
+		if _, err := db.Exec("UPDATE posts SET score = ?, hot_score = ?, controversy_score = ? WHERE id = ?",
+			u.score, u.hot, u.controversy, u.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package datahandlers
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ReputationWeights, bir oyun içeriğin yazarına kazandırdığı (veya kaybettirdiği) puanlardır.
+// AcceptedAnswer, cevabı kabul edilen yorumun yazarına verilir.
+type ReputationWeights struct {
+	PostLike       int
+	PostDislike    int
+	CommentLike    int
+	CommentDislike int
+	AcceptedAnswer int
+}
+
+// Weight, verilen oyun puan karşılığıdır; voteType 0 oy yok demektir.
+func (w ReputationWeights) Weight(comment bool, voteType int) int {
+	switch {
+	case voteType == 0:
+		return 0
+	case comment && voteType > 0:
+		return w.CommentLike
+	case comment:
+		return w.CommentDislike
+	case voteType > 0:
+		return w.PostLike
+	default:
+		return w.PostDislike
+	}
+}
+
+// Ağırlıkların app_settings tablosunda saklanan parmak izi.
+func (w ReputationWeights) String() string {
+	return fmt.Sprintf("post_like=%d,post_dislike=%d,comment_like=%d,comment_dislike=%d,accepted_answer=%d",
+		w.PostLike, w.PostDislike, w.CommentLike, w.CommentDislike, w.AcceptedAnswer)
+}
+
+// Reputation, kullanıcının saklanan puanıdır.
+func Reputation(userID int) (int, error) {
+	var reputation int
+	err := DB.QueryRow("SELECT reputation FROM users WHERE id = ?", userID).Scan(&reputation)
+	return reputation, err
+}
+
+// ReputationAndRole, yetki kontrolleri için kullanıcının puanını ve rolünü döndürür.
+func ReputationAndRole(userID int) (int, string, error) {
+	var reputation int
+	var role string
+	err := DB.QueryRow("SELECT reputation, COALESCE(role, 'user') FROM users WHERE id = ?", userID).Scan(&reputation, &role)
+	return reputation, role, err
+}
+
+// VoteTargetAuthor, oylanan gönderinin veya yorumun yazarını döndürür. İçerik silinmişse 0 döner.
+func VoteTargetAuthor(postID, commentID int64) (int, error) {
+	var authorID int
+	var err error
+	if commentID != 0 {
+		err = DB.QueryRow("SELECT user_id FROM comments WHERE id = ? AND deleted = 0", commentID).Scan(&authorID)
+	} else {
+		err = DB.QueryRow("SELECT user_id FROM posts WHERE id = ? AND deleted = 0", postID).Scan(&authorID)
+	}
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return authorID, err
+}
+
+// AddReputation, kullanıcının puanını delta kadar değiştirir.
+func AddReputation(userID, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	_, err := DB.Exec("UPDATE users SET reputation = reputation + ? WHERE id = ?", delta, userID)
+	return err
+}
+
+// Kullanıcı başına oylardan ve kabul edilen cevaplardan gelen toplam puan. Kendi içeriğine
+// verilen oylar ve kendi sorusunda kabul ettiği cevaplar sayılmaz.
+const reputationTotals = `
+	SELECT owner, SUM(points) AS total FROM (
+		SELECT p.user_id AS owner, v.user_id AS voter, CASE WHEN v.vote_type > 0 THEN ? ELSE ? END AS points
+		FROM votes v JOIN posts p ON p.id = v.post_id
+		WHERE v.comment_id IS NULL AND p.deleted = 0
+		UNION ALL
+		SELECT c.user_id, v.user_id, CASE WHEN v.vote_type > 0 THEN ? ELSE ? END
+		FROM votes v JOIN comments c ON c.id = v.comment_id
+		WHERE c.deleted = 0
+		UNION ALL
+		SELECT c.user_id, p.user_id, ?
+		FROM posts p JOIN comments c ON c.id = p.accepted_comment_id
+		WHERE p.deleted = 0 AND c.deleted = 0
+	) WHERE owner != voter GROUP BY owner`
+
+// RecalculateReputation, kullanıcının puanını oylardan baştan hesaplar; userID 0 ise tüm
+// kullanıcılarınkini. İçerik silindiğinde ve ağırlıklar değiştiğinde kullanılır.
+func RecalculateReputation(w ReputationWeights, userID int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := recalculateReputation(tx, w, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func recalculateReputation(tx *sql.Tx, w ReputationWeights, userID int) error {
+	reset := "UPDATE users SET reputation = 0 WHERE ? IN (0, id)"
+	if _, err := tx.Exec(reset, userID); err != nil {
+		return err
+	}
+	update := "UPDATE users SET reputation = t.total FROM (" + reputationTotals + ") t WHERE t.owner = users.id AND ? IN (0, users.id)"
+	_, err := tx.Exec(update, w.PostLike, w.PostDislike, w.CommentLike, w.CommentDislike, w.AcceptedAnswer, userID)
+	return err
+}
+
+// SyncReputation, puanlar en son farklı ağırlıklarla (veya hiç) hesaplanmışsa tüm
+// kullanıcıların puanını yeniden hesaplar. Yeniden hesaplama yapıldıysa true döner.
+func SyncReputation(w ReputationWeights) (bool, error) {
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var stored string
+	err = tx.QueryRow("SELECT value FROM app_settings WHERE name = 'reputation_weights'").Scan(&stored)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+	if stored == w.String() {
+		return false, nil
+	}
+	if err := recalculateReputation(tx, w, 0); err != nil {
+		return false, fmt.Errorf("error recalculating reputation: %v", err)
+	}
+	_, err = tx.Exec(`INSERT INTO app_settings (name, value) VALUES ('reputation_weights', ?)
+		ON CONFLICT(name) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP`, w.String())
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
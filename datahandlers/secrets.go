@@ -0,0 +1,31 @@
+package datahandlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+)
+
+// Secret, adı verilen uygulama anahtarını döndürür; yoksa size baytlık rastgele
+// bir anahtar üretip kaydeder. Böylece imzalı çerezler sunucu yeniden başlatıldığında da geçerli kalır.
+func Secret(name string, size int) ([]byte, error) {
+	var value []byte
+	err := DB.QueryRow("SELECT value FROM app_secrets WHERE name = ?", name).Scan(&value)
+	if err == nil {
+		return value, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error reading secret %s: %v", name, err)
+	}
+
+	value = make([]byte, size)
+	if _, err := rand.Read(value); err != nil {
+		return nil, err
+	}
+	// Aynı anda başlayan iki süreç varsa ilk kaydedilen anahtar kullanılır
+	if _, err := DB.Exec("INSERT OR IGNORE INTO app_secrets (name, value) VALUES (?, ?)", name, value); err != nil {
+		return nil, fmt.Errorf("error saving secret %s: %v", name, err)
+	}
+	err = DB.QueryRow("SELECT value FROM app_secrets WHERE name = ?", name).Scan(&value)
+	return value, err
+}
@@ -0,0 +1,108 @@
+package events // Açık sayfalara canlı güncelleme gönderen süreç içi yayın/abone merkezi ve SSE uç noktası
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Konular: ana sayfa akışı, tek bir gönderinin sayfası ve bir kullanıcının açık sekmeleri
+const FeedTopic = "feed"
+
+// PostTopic, gönderi sayfasının konusudur (yeni yorumlar ve oy sayıları).
+func PostTopic(postID int64) string {
+	return fmt.Sprintf("post:%d", postID)
+}
+
+// UserTopic, kullanıcıya özel olayların (ör. okunmamış bildirim sayısı) konusudur.
+func UserTopic(userID int) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// Event, abonelere gönderilen olaydır; Data JSON olarak kodlanır.
+type Event struct {
+	Name string
+	Data interface{}
+}
+
+// Bir abonenin tamponu dolarsa (yavaş istemci) yeni olaylar ona gönderilmez
+const subscriberBuffer = 16
+
+// ErrTooManySubscribers, merkez en fazla abone sayısına ulaştığında döner.
+var ErrTooManySubscribers = errors.New("too many live connections")
+
+// Subscriber, bir veya daha fazla konuya abone olan bağlantıdır.
+type Subscriber struct {
+	C      chan Event
+	topics []string
+}
+
+// Hub, konulara göre aboneleri tutar ve yayınlanan olayları onlara iletir.
+type Hub struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscriber]struct{}
+	count  int
+	max    int
+}
+
+// NewHub, en fazla max aboneye izin veren bir merkez oluşturur (0 sınırsız).
+func NewHub(max int) *Hub {
+	return &Hub{topics: make(map[string]map[*Subscriber]struct{}), max: max}
+}
+
+// Subscribe, verilen konulara abone olan yeni bir bağlantı oluşturur.
+func (h *Hub) Subscribe(topics ...string) (*Subscriber, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.max > 0 && h.count >= h.max {
+		return nil, ErrTooManySubscribers
+	}
+	s := &Subscriber{C: make(chan Event, subscriberBuffer), topics: topics}
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*Subscriber]struct{})
+		}
+		h.topics[topic][s] = struct{}{}
+	}
+	h.count++
+	return s, nil
+}
+
+// Unsubscribe, bağlantıyı tüm konulardan çıkarır.
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, topic := range s.topics {
+		delete(h.topics[topic], s)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	h.count--
+}
+
+// Publish, olayı konunun tüm abonelerine gönderir. Beklemez: tamponu dolu abonelere
+// olay iletilmez.
+func (h *Hub) Publish(topic string, e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for s := range h.topics[topic] {
+		select {
+		case s.C <- e:
+		default:
+		}
+	}
+}
+
+// Uygulamanın kullandığı merkez
+const maxSubscribers = 1000
+
+var hub = NewHub(maxSubscribers)
+
+// Publish, olayı uygulamanın merkezinde yayınlar.
+func Publish(topic, name string, data interface{}) {
+	hub.Publish(topic, Event{Name: name, Data: data})
+}
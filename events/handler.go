@@ -0,0 +1,74 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"form-project/datahandlers"
+	"form-project/utils"
+)
+
+// Bağlantının ara sunucularca kapatılmaması için gönderilen boş yorum aralığı
+const heartbeatInterval = 25 * time.Second
+
+// Handler, /events adresinde Server-Sent Events akışı açar. Her bağlantı ana sayfa akışına,
+// ?post=<id> verilmişse o gönderiye ve oturum varsa kullanıcının kendi konusuna abone olur.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+
+	topics := []string{FeedTopic}
+	if postParam := r.URL.Query().Get("post"); postParam != "" {
+		postID, err := strconv.ParseInt(postParam, 10, 64)
+		if err != nil {
+			utils.WriteError(w, r, utils.BadRequest("Invalid post ID", err))
+			return
+		}
+		topics = append(topics, PostTopic(postID))
+	}
+	if session, err := datahandlers.GetSession(r); err == nil && session != nil {
+		topics = append(topics, UserTopic(session.UserID))
+	}
+
+	sub, err := hub.Subscribe(topics...)
+	if err != nil {
+		utils.WriteError(w, r, utils.NewError(http.StatusServiceUnavailable, "Live updates are temporarily unavailable", err))
+		return
+	}
+	defer hub.Unsubscribe(sub)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx gibi ara sunucular akışı tamponlamasın
+	// Bağlantı koparsa tarayıcı 5 saniye sonra yeniden bağlanır
+	fmt.Fprint(w, "retry: 5000\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case e := <-sub.C:
+			data, err := json.Marshal(e.Data)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Name, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package flash // Yönlendirmeler arasında kullanıcıya bir kez gösterilecek bildirimleri (flash mesajları) taşıyan paket
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"form-project/security"
+)
+
+const cookieName = "flash"
+
+// Mesaj seviyeleri; şablonda "flash-<seviye>" CSS sınıfı olarak kullanılır.
+const (
+	Success = "success"
+	Info    = "info"
+	Warning = "warning"
+	Error   = "error"
+)
+
+// Message, bir sonraki sayfada gösterilecek tek bir bildirimdir.
+type Message struct {
+	Level string `json:"level"`
+	Text  string `json:"text"`
+}
+
+// Add, mesajı imzalı flash çerezine ekler. Mesaj, bir sonraki HTML sayfası işlenirken gösterilir.
+// Aynı istekte daha önce eklenen mesajlar korunur.
+func Add(w http.ResponseWriter, r *http.Request, level, text string) {
+	messages := append(pending(w, r), Message{Level: level, Text: text})
+
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return
+	}
+	setCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    security.Sign(base64.RawURLEncoding.EncodeToString(data)),
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func AddSuccess(w http.ResponseWriter, r *http.Request, text string) { Add(w, r, Success, text) }
+func AddInfo(w http.ResponseWriter, r *http.Request, text string)    { Add(w, r, Info, text) }
+func AddWarning(w http.ResponseWriter, r *http.Request, text string) { Add(w, r, Warning, text) }
+func AddError(w http.ResponseWriter, r *http.Request, text string)   { Add(w, r, Error, text) }
+
+// Pop, bekleyen mesajları (aynı istekte eklenenler dahil) döndürür ve çerezi temizler; her
+// mesaj yalnızca bir kez gösterilir.
+func Pop(w http.ResponseWriter, r *http.Request) []Message {
+	_, set := responseCookie(w)
+	if _, err := r.Cookie(cookieName); err != nil && !set {
+		return nil
+	}
+
+	messages := pending(w, r)
+	setCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return messages
+}
+
+// Gösterilmeyi bekleyen mesajlar: yanıtta bu istekte yazılmış bir flash çerezi varsa
+// onun, yoksa istekle gelen çerezin mesajları
+func pending(w http.ResponseWriter, r *http.Request) []Message {
+	if value, ok := responseCookie(w); ok {
+		return decode(value)
+	}
+	cookie, err := r.Cookie(cookieName)
+	if err != nil {
+		return nil
+	}
+	return decode(cookie.Value)
+}
+
+// Yanıta bu istekte eklenen son flash çerezinin değeri
+func responseCookie(w http.ResponseWriter) (string, bool) {
+	cookies := (&http.Response{Header: http.Header{"Set-Cookie": w.Header()["Set-Cookie"]}}).Cookies()
+	for i := len(cookies) - 1; i >= 0; i-- {
+		if cookies[i].Name == cookieName {
+			return cookies[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// Yanıttaki önceki flash çerezlerini yenisiyle değiştirir; diğer çerezlere dokunmaz.
+func setCookie(w http.ResponseWriter, cookie *http.Cookie) {
+	header := w.Header()
+	var kept []string
+	for _, line := range header["Set-Cookie"] {
+		if !strings.HasPrefix(line, cookieName+"=") {
+			kept = append(kept, line)
+		}
+	}
+	header["Set-Cookie"] = kept
+	http.SetCookie(w, cookie)
+}
+
+// Çerez değerindeki mesajları okur; imzası geçersiz veya bozuk değerler yok sayılır.
+func decode(signed string) []Message {
+	value, ok := security.Verify(signed)
+	if !ok {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+
+	var messages []Message
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil
+	}
+	return messages
+}
@@ -0,0 +1,83 @@
+package flash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"form-project/security"
+)
+
+// Yanıttaki çerezleri bir sonraki isteğe taşır
+func nextRequest(t *testing.T, w *httptest.ResponseRecorder) *http.Request {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		if c.Name == cookieName && c.MaxAge >= 0 {
+			r.AddCookie(c)
+		}
+	}
+	return r
+}
+
+func TestAddMergesMessagesInOneRequest(t *testing.T) {
+	security.SetSigningKey([]byte("test signing key"))
+
+	// Önceki istekten kalan mesaj, bu istekte eklenen iki mesajla birlikte korunur
+	w := httptest.NewRecorder()
+	AddInfo(w, httptest.NewRequest(http.MethodGet, "/", nil), "first")
+	r := nextRequest(t, w)
+
+	w = httptest.NewRecorder()
+	w.Header().Add("Set-Cookie", "session_token=x; Path=/")
+	AddSuccess(w, r, "second")
+	AddWarning(w, r, "third")
+
+	var flashCookies int
+	for _, c := range w.Result().Cookies() {
+		if c.Name == cookieName {
+			flashCookies++
+		}
+	}
+	if flashCookies != 1 || len(w.Result().Cookies()) != 2 {
+		t.Errorf("Set-Cookie = %q, want the session cookie and one flash cookie", w.Header()["Set-Cookie"])
+	}
+
+	want := []Message{{Info, "first"}, {Success, "second"}, {Warning, "third"}}
+	w2 := httptest.NewRecorder()
+	if got := Pop(w2, nextRequest(t, w)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Pop = %v, want %v", got, want)
+	}
+	if r := nextRequest(t, w2); len(r.Cookies()) != 0 {
+		t.Errorf("Pop did not clear the flash cookie: %v", w2.Header()["Set-Cookie"])
+	}
+}
+
+func TestPopIncludesMessagesAddedInSameRequest(t *testing.T) {
+	security.SetSigningKey([]byte("test signing key"))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	AddError(w, r, "failed")
+	if got := Pop(w, r); !reflect.DeepEqual(got, []Message{{Error, "failed"}}) {
+		t.Errorf("Pop = %v", got)
+	}
+	// Gösterilen mesaj yeniden eklenmez
+	AddInfo(w, r, "later")
+	if got := Pop(httptest.NewRecorder(), nextRequest(t, w)); !reflect.DeepEqual(got, []Message{{Info, "later"}}) {
+		t.Errorf("Pop after Pop and Add = %v", got)
+	}
+}
+
+func TestTamperedCookieIgnored(t *testing.T) {
+	security.SetSigningKey([]byte("test signing key"))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: cookieName, Value: "W3sibGV2ZWwiOiJlcnJvciIsInRleHQiOiJ4In1d.bad"})
+	w := httptest.NewRecorder()
+	AddInfo(w, r, "ok")
+	if got := Pop(httptest.NewRecorder(), nextRequest(t, w)); !reflect.DeepEqual(got, []Message{{Info, "ok"}}) {
+		t.Errorf("Pop = %v", got)
+	}
+}
@@ -0,0 +1,133 @@
+package healthhandlers // Docker ve yük dengeleyiciler için canlılık (liveness) ve hazır olma (readiness) kontrolleri
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"form-project/datahandlers"
+	"form-project/media"
+	"form-project/render"
+)
+
+const (
+	checkTimeout = 2 * time.Second
+)
+
+var startedAt = time.Now()
+
+// Tek bir hazır olma kontrolünün sonucu
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type readyResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// /readyz tarafından sırayla çalıştırılan kontroller
+var checks = []struct {
+	name string
+	run  func(ctx context.Context) error
+}{
+	{"database", checkDatabase},
+	{"migrations", checkMigrations},
+	{"uploads", checkUploadsWritable},
+	{"templates", checkTemplates},
+}
+
+// Süreç ayakta olduğu sürece 200 döndürür; bağımlılıkları kontrol etmez.
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"status": "ok",
+		"uptime": time.Since(startedAt).Round(time.Second).String(),
+	})
+}
+
+// Uygulamanın istek karşılamaya hazır olup olmadığını bağımlılıklarıyla birlikte kontrol eder.
+func ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+
+	response := readyResponse{Status: "ok", Checks: make(map[string]CheckResult)}
+	for _, check := range checks {
+		start := time.Now()
+		result := CheckResult{Status: "ok"}
+		if err := check.run(ctx); err != nil {
+			result.Status = "fail"
+			result.Error = err.Error()
+			response.Status = "fail"
+		}
+		result.DurationMS = time.Since(start).Milliseconds()
+		response.Checks[check.name] = result
+	}
+
+	status := http.StatusOK
+	if response.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, response)
+}
+
+func checkDatabase(ctx context.Context) error {
+	if datahandlers.DB == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	var one int
+	return datahandlers.DB.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+func checkMigrations(ctx context.Context) error {
+	if datahandlers.DB == nil {
+		return fmt.Errorf("database connection is not initialized")
+	}
+	current, err := datahandlers.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if latest := datahandlers.LatestSchemaVersion(); current != latest {
+		return fmt.Errorf("schema version %d, expected %d", current, latest)
+	}
+	return nil
+}
+
+// Yükleme deposuna gizli bir nesne yazıp silerek yazılabilir olduğunu doğrular.
+func checkUploadsWritable(ctx context.Context) error {
+	const key = ".readyz"
+	if err := media.Store.Put(ctx, key, []byte("ok"), "text/plain"); err != nil {
+		return fmt.Errorf("%s store: %w", media.Store.Name(), err)
+	}
+	return media.Store.Delete(ctx, key)
+}
+
+// Şablonların başlangıçta ayrıştırılıp önbelleğe alındığını doğrular.
+func checkTemplates(ctx context.Context) error {
+	return render.Check()
+}
+
+// Docker HEALTHCHECK için: verilen adrese istek atar, 200 dışında bir yanıtta hata döndürür.
+func Probe(url string) error {
+	client := http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
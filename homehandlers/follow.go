@@ -0,0 +1,44 @@
+package homehandlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"form-project/datahandlers"
+	"form-project/flash"
+	"form-project/utils"
+)
+
+// FollowCategoryHandler, "category" alanındaki kategoriyi takip eder ya da ("action=unfollow")
+// takibi bırakır. Takip edilen kategorilerdeki yeni gönderiler bildirim oluşturur.
+func FollowCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	category := strings.TrimSpace(r.FormValue("category"))
+	if category == "" || utf8.RuneCountInString(category) > datahandlers.MaxCategoryLength {
+		utils.WriteError(w, r, utils.BadRequest("Invalid category", nil))
+		return
+	}
+
+	if r.FormValue("action") == "unfollow" {
+		err = datahandlers.UnfollowCategory(session.UserID, category)
+		flash.AddSuccess(w, r, "You unfollowed "+category+".")
+	} else {
+		err = datahandlers.FollowCategory(session.UserID, category)
+		flash.AddSuccess(w, r, "You are now following "+category+". New posts in it will notify you.")
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	http.Redirect(w, r, "/?"+url.Values{"category": {category}}.Encode(), http.StatusSeeOther)
+}
@@ -5,18 +5,20 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"math/rand"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
 	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
+	"form-project/config"
 	"form-project/datahandlers"
+	"form-project/flash"
+	"form-project/media"
+	"form-project/metrics"
+	"form-project/render"
 	"form-project/utils"
 
 	"github.com/go-playground/validator"
@@ -49,20 +51,84 @@ type Post struct {
 	DislikeCount        int
 	Username            string
 	CommentCount        int
+	ImagePath           string // Görsel yoksa boş; akışta küçük resmi gösterilir
+	AvatarPath          string // Yazarın profil fotoğrafı; yoksa boş
+	AuthorReputation    int    // Yazarın puanı
+	IsQuestion          bool
+	Solved              bool // Sorunun kabul edilmiş bir cevabı var
 }
 
 type RegisterTemplateData struct {
+	render.Page
 	ErrorMessages map[string]string
 	Email         string
 	Username      string
 }
 
+// HomeTemplateData, ana sayfanın verisidir.
+type HomeTemplateData struct {
+	render.Page
+	Posts             []Post
+	Filter            string // most_liked, most_commented, following, solved, unsolved veya boş
+	Category          string
+	FollowingCategory bool // Oturumdaki kullanıcı seçili kategoriyi takip ediyor mu
+	Sorts             []SortOption
+}
+
+// SortOption, ana sayfadaki sıralama bağlantılarından biridir.
+type SortOption struct {
+	Label  string
+	URL    string // Arama, kategori ve filtreyi koruyan adres
+	Active bool
+}
+
+// Ana sayfa sıralamaları ("sort" parametresi); boş değer en yeniden eskiye sıralar.
+var sortOptions = []struct{ value, label string }{
+	{"", "New"},
+	{"hot", "Hot"},
+	{"top_day", "Top: Day"},
+	{"top_week", "Top: Week"},
+	{"top_month", "Top: Month"},
+	{"top_all", "Top: All Time"},
+	{"controversial", "Controversial"},
+}
+
+// "top_*" sıralamalarının kapsadığı süre; 0 tüm zamanlar demektir.
+var topPeriods = map[string]time.Duration{
+	"top_day":   24 * time.Hour,
+	"top_week":  7 * 24 * time.Hour,
+	"top_month": 30 * 24 * time.Hour,
+	"top_all":   0,
+}
+
+// Geçerli sorgu parametrelerini koruyarak sıralama bağlantılarını üretir.
+func buildSortOptions(query url.Values, current string) []SortOption {
+	options := make([]SortOption, 0, len(sortOptions))
+	for _, o := range sortOptions {
+		q := url.Values{}
+		for _, key := range []string{"search", "category", "filter"} {
+			if v := query.Get(key); v != "" {
+				q.Set(key, v)
+			}
+		}
+		if o.value != "" {
+			q.Set("sort", o.value)
+		}
+		link := "/"
+		if len(q) > 0 {
+			link += "?" + q.Encode()
+		}
+		options = append(options, SortOption{Label: o.label, URL: link, Active: o.value == current})
+	}
+	return options
+}
+
 type AdminTemplateData struct {
+	render.Page
 	Users      []User
 	Posts      []Post
 	Categories []Category
-	LoggedIn   bool
-	IsAdmin    bool
+	RoleQuotas []media.RoleQuota
 }
 
 type Category struct {
@@ -72,33 +138,9 @@ type Category struct {
 
 var (
 	validate    = validator.New()
-	config      Config
 	registering = false // Kaydetme veya giriş yapma işlemini ayırt etmek için
 )
 
-type Config struct {
-	GoogleClientID       string `json:"google_client_id"`
-	GoogleClientSecret   string `json:"google_client_secret"`
-	GitHubClientID       string `json:"github_client_id"`
-	GitHubClientSecret   string `json:"github_client_secret"`
-	FacebookClientID     string `json:"facebook_client_id"`
-	FacebookClientSecret string `json:"facebook_client_secret"`
-}
-
-func loadConfig() {
-	file, err := os.Open("config.json")
-	if err != nil {
-		log.Fatalf("Failed to open config file: %s", err)
-	}
-	defer file.Close()
-
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&config)
-	if err != nil {
-		log.Fatalf("Failed to decode config file: %s", err)
-	}
-}
-
 // OAuth 2.0 yapılandırmalarını tutmak için genel değişkenler
 var (
 	googleOauthConfig      *oauth2.Config // googleOauthConfig değişkeni, Google OAuth 2.0 yapılandırmasını tutar.
@@ -109,28 +151,31 @@ var (
 
 // Paket yüklenirken otomatik olarak çalışır.
 func init() {
-	loadConfig()
+	cfg, err := config.Load(config.DefaultPath)
+	if err != nil {
+		log.Fatal(err)
+	}
 	// Google OAuth 2.0 yapılandırması oluşturulur.
 	googleOauthConfig = &oauth2.Config{
 		RedirectURL:  "http://localhost:8065/google/callback",
-		ClientID:     config.GoogleClientID,
-		ClientSecret: config.GoogleClientSecret,
+		ClientID:     cfg.GoogleClientID,
+		ClientSecret: cfg.GoogleClientSecret,
 		Scopes:       []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile"},
 		Endpoint:     google.Endpoint,
 	}
 
 	githubOauthConfig = &oauth2.Config{
 		RedirectURL:  "http://localhost:8065/github/callback",
-		ClientID:     config.GitHubClientID,
-		ClientSecret: config.GitHubClientSecret,
+		ClientID:     cfg.GitHubClientID,
+		ClientSecret: cfg.GitHubClientSecret,
 		Scopes:       []string{"user:email"},
 		Endpoint:     github.Endpoint,
 	}
 
 	facebookOauthConfig = &oauth2.Config{
 		RedirectURL:  "http://localhost:8065/facebook/callback",
-		ClientID:     config.FacebookClientID,
-		ClientSecret: config.FacebookClientSecret,
+		ClientID:     cfg.FacebookClientID,
+		ClientSecret: cfg.FacebookClientSecret,
 		Scopes:       []string{"email"},
 		Endpoint:     facebook.Endpoint,
 	}
@@ -167,61 +212,65 @@ func HandleGoogleRegister(w http.ResponseWriter, r *http.Request) {
 
 // Ana sayfayı görüntüler.
 func HomeHandler(w http.ResponseWriter, r *http.Request) {
-	session, err := datahandlers.GetSession(r)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+	// "/" deseni eşleşmeyen tüm yolları yakalar
+	if r.URL.Path != "/" {
+		utils.WriteError(w, r, utils.NotFound("Page not found"))
 		return
 	}
 
 	searchQuery := r.URL.Query().Get("search")
 	category := r.URL.Query().Get("category")
 	filter := r.URL.Query().Get("filter")
-
-	posts, err := getFilteredPosts(searchQuery, category, filter, nil)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-		return
+	sort := r.URL.Query().Get("sort")
+	if _, ok := topPeriods[sort]; !ok && sort != "hot" && sort != "controversial" {
+		sort = ""
 	}
 
-	// Kullanıcının admin olup olmadığını kontrol et
-	isAdmin := false
-	if session != nil {
-		isAdmin, err = CheckIfAdmin(int64(session.UserID))
-		if err != nil {
-			utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-			return
-		}
+	// Şablon verilerini oluştur; oturum ve admin bilgisi ortak sayfa bağlamından gelir
+	data := HomeTemplateData{
+		Page:     render.NewPage(w, r),
+		Filter:   filter,
+		Category: category,
+		Sorts:    buildSortOptions(r.URL.Query(), sort),
 	}
-
-	// Şablon verilerini oluştur
-	data := AdminTemplateData{
-		Posts:    posts,
-		LoggedIn: session != nil,
-		IsAdmin:  isAdmin,
+	viewerID := 0
+	if data.CurrentUser != nil {
+		viewerID = data.CurrentUser.ID
+	}
+	if filter == "following" && viewerID == 0 {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
 	}
 
-	// Şablonu işleme
-	tmpl, err := template.ParseFiles("templates/index.html")
+	posts, err := getFilteredPosts(searchQuery, category, filter, sort, nil, viewerID)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	data.Posts = posts
+	if category != "" && viewerID != 0 {
+		if data.FollowingCategory, err = datahandlers.IsFollowingCategory(viewerID, category); err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+	}
 
-	err = tmpl.Execute(w, data)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+	if err := render.HTML(w, http.StatusOK, "index", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
 // Verilen filtrelere (arama sorgusu, kategori, filtre türü, kullanıcı ID'si) göre gönderileri veritabanından çeker.
-func getFilteredPosts(searchQuery, category, filter string, userID *int) ([]Post, error) {
+// "following" filtresi viewerID'nin takip ettiği kullanıcıların, kategorilerin ve gönderilerin akışıdır;
+// "solved" ve "unsolved" kabul edilmiş cevabı olan ve olmayan sorulardır. sort verilmişse (hot,
+// top_day/week/month/all, controversial) gönderiler önceden hesaplanmış puanlara göre sıralanır.
+func getFilteredPosts(searchQuery, category, filter, sort string, userID *int, viewerID int) ([]Post, error) {
 	query := `SELECT posts.id, posts.user_id, posts.title, posts.content, posts.categories, posts.created_at, users.username,
-                     COALESCE(SUM(CASE WHEN votes.vote_type = 1 THEN 1 ELSE 0 END), 0) AS like_count,
-                     COALESCE(SUM(CASE WHEN votes.vote_type = -1 THEN 1 ELSE 0 END), 0) AS dislike_count,
-                     (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted = 0) AS comment_count
+                     posts.like_count, posts.dislike_count, posts.comment_count,
+                     COALESCE(posts.image_path, ''), COALESCE(users.profile_picture_path, ''), users.reputation,
+                     posts.is_question, posts.accepted_comment_id IS NOT NULL
               FROM posts
               JOIN users ON posts.user_id = users.id
-              LEFT JOIN votes ON votes.post_id = posts.id
               WHERE posts.deleted = 0`
 
 	args := []interface{}{}  // Sorgu parametreleri için
@@ -244,17 +293,36 @@ func getFilteredPosts(searchQuery, category, filter string, userID *int) ([]Post
 		args = append(args, *userID, *userID)
 	}
 
+	switch filter {
+	case "following":
+		conditions = append(conditions, datahandlers.FollowingFeedCondition)
+		args = append(args, viewerID, viewerID, viewerID, viewerID)
+	case "solved":
+		conditions = append(conditions, "posts.is_question = 1 AND posts.accepted_comment_id IS NOT NULL")
+	case "unsolved":
+		conditions = append(conditions, "posts.is_question = 1 AND posts.accepted_comment_id IS NULL")
+	}
+
+	if period := topPeriods[sort]; period > 0 {
+		conditions = append(conditions, "posts.created_at >= ?")
+		args = append(args, time.Now().Add(-period))
+	}
+
 	if len(conditions) > 0 {
 		query += " AND " + strings.Join(conditions, " AND ")
 	}
 
-	query += " GROUP BY posts.id"
-
-	switch filter {
-	case "most_liked":
-		query += " ORDER BY like_count DESC"
-	case "most_commented":
-		query += " ORDER BY comment_count DESC"
+	switch {
+	case sort == "hot":
+		query += " ORDER BY posts.hot_score DESC"
+	case sort == "controversial":
+		query += " ORDER BY posts.controversy_score DESC, posts.created_at DESC"
+	case strings.HasPrefix(sort, "top_"):
+		query += " ORDER BY posts.score DESC, posts.created_at DESC"
+	case filter == "most_liked":
+		query += " ORDER BY posts.like_count DESC"
+	case filter == "most_commented":
+		query += " ORDER BY posts.comment_count DESC"
 	default:
 		query += " ORDER BY posts.created_at DESC"
 	}
@@ -269,7 +337,7 @@ func getFilteredPosts(searchQuery, category, filter string, userID *int) ([]Post
 	for rows.Next() {
 		var post Post
 		var categoriesJSON string
-		if err := rows.Scan(&post.ID, &post.UserID, &post.Title, &post.Content, &categoriesJSON, &post.CreatedAt, &post.Username, &post.LikeCount, &post.DislikeCount, &post.CommentCount); err != nil {
+		if err := rows.Scan(&post.ID, &post.UserID, &post.Title, &post.Content, &categoriesJSON, &post.CreatedAt, &post.Username, &post.LikeCount, &post.DislikeCount, &post.CommentCount, &post.ImagePath, &post.AvatarPath, &post.AuthorReputation, &post.IsQuestion, &post.Solved); err != nil {
 			return nil, err
 		}
 		if err := json.Unmarshal([]byte(categoriesJSON), &post.Categories); err != nil {
@@ -320,31 +388,23 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 				errorMessages["Email"] = err.Error() // Generic error message
 			}
 
-			renderRegisterTemplate(w, RegisterTemplateData{ErrorMessages: errorMessages})
+			renderRegisterTemplate(w, r, RegisterTemplateData{ErrorMessages: errorMessages})
 			return
 		}
 	default: // GET request
-		tmpl, err := template.ParseFiles("templates/register.html")
-		if err != nil {
-			utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-			return
-		}
-
 		// Şablon verilerini hazırla
 		data := RegisterTemplateData{
 			ErrorMessages: errorMessages, // Hata mesajlarını şablona aktar
 			Email:         email,
 		}
-		err = tmpl.Execute(w, data)
-		if err != nil {
-			utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-		}
+		renderRegisterTemplate(w, r, data)
 	}
 }
 
+// Hata mesajını flash olarak ekleyip giriş sayfasına yönlendirir.
 func loginError(w http.ResponseWriter, r *http.Request, message string) {
-	redirectURL := "/login?error=" + message
-	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+	flash.AddError(w, r, message)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
 func registerUser(w http.ResponseWriter, r *http.Request) error {
@@ -392,7 +452,6 @@ func registerUser(w http.ResponseWriter, r *http.Request) error {
 			return err
 		}
 		if count > 0 {
-			registerError(w, r, "username already exists")
 			return fmt.Errorf("username already exists")
 		}
 
@@ -433,18 +492,30 @@ func registerUser(w http.ResponseWriter, r *http.Request) error {
 		Secure:   true, // If using HTTPS
 	})
 
+	flash.AddSuccess(w, r, fmt.Sprintf("Welcome, %s! Your account has been created.", user.Username.String))
 	http.Redirect(w, r, "/myprofil", http.StatusSeeOther) // Redirect to profile page on successful registration
 
 	return nil // Successful registration
 }
 
-func registerError(w http.ResponseWriter, r *http.Request, s string) {
-	panic("unimplemented")
+// Hata mesajını flash olarak ekleyip kayıt sayfasına yönlendirir.
+func registerError(w http.ResponseWriter, r *http.Request, message string) {
+	flash.AddError(w, r, message)
+	http.Redirect(w, r, "/register", http.StatusSeeOther)
 }
 
 func saveUser(user *User) error {
-	_, err := datahandlers.DB.Exec("INSERT INTO users (email, username, password) VALUES (?, ?, ?)", user.Email, user.Username, user.Password)
-	return err
+	result, err := datahandlers.DB.Exec("INSERT INTO users (email, username, password, created_at) VALUES (?, ?, ?, ?)", user.Email, user.Username, user.Password, time.Now())
+	if err != nil {
+		return err
+	}
+	// Oturum yeni kullanıcının ID'si ile açılır
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	user.ID = int(id)
+	return nil
 }
 
 func getUserByEmail(email string) (*User, error) {
@@ -484,16 +555,17 @@ func getGoogleUserByEmail(email string) (int64, error) {
 }
 
 // Kayıt formunu göstermek için HTML şablonunu render eder.
-func renderRegisterTemplate(w http.ResponseWriter, data RegisterTemplateData) {
-	tmpl, err := template.ParseFiles("templates/register.html")
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-		return
+func renderRegisterTemplate(w http.ResponseWriter, r *http.Request, data RegisterTemplateData) {
+	data.Page = render.NewPage(w, r)
+	if err := render.HTML(w, http.StatusOK, "register", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 	}
+}
 
-	err = tmpl.Execute(w, data)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+// Giriş formunu gösterir; hata mesajları flash olarak sayfa bağlamında gelir.
+func renderLoginTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := render.HTML(w, http.StatusOK, "login", render.NewPage(w, r)); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
@@ -507,11 +579,6 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Hata mesajını taşımak için bir yapı oluşturun
-	tmplData := struct {
-		Error string
-	}{}
-
 	if r.Method == http.MethodPost {
 		email := r.FormValue("email")
 		password := r.FormValue("password")
@@ -520,20 +587,12 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		// Kullanıcının banlı olup olmadığını kontrol et
 		banned, err := checkIfBanned(email)
 		if err != nil {
-			utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+			utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 		if banned {
-			tmplData.Error = "Bu kullanıcı banlanmış."
-			tmpl, err := template.ParseFiles("templates/login.html")
-			if err != nil {
-				utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-				return
-			}
-			err = tmpl.Execute(w, tmplData)
-			if err != nil {
-				utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-			}
+			metrics.Login("password", false)
+			loginError(w, r, "Bu kullanıcı banlanmış.")
 			return
 		}
 
@@ -542,30 +601,32 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 			code := r.FormValue("code")
 			token, err := googleOauthConfig.Exchange(r.Context(), code)
 			if err != nil {
-				http.Error(w, "Failed to exchange token", http.StatusInternalServerError)
+				metrics.Login("google", false)
+				utils.HandleErr(w, r, err, "Failed to exchange token", http.StatusInternalServerError)
 				return
 			}
 
 			email, _, err := getEmailAndNameFromGoogle(token)
 			if err != nil {
-				http.Error(w, "Failed to get user info from Google", http.StatusInternalServerError)
+				utils.HandleErr(w, r, err, "Failed to get user info from Google", http.StatusInternalServerError)
 				return
 			}
 
 			// Google ile kayıtlı kullanıcıyı bul
 			userID, err := getGoogleUserByEmail(email)
 			if err != nil {
+				metrics.Login("google", false)
 				if err == sql.ErrNoRows {
-					utils.HandleErr(w, err, "User not found. Please register first.", http.StatusUnauthorized)
+					loginError(w, r, "User not found. Please register first.")
 					return
 				}
-				utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+				utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 				return
 			}
 
 			sessionToken, err := createSession(userID)
 			if err != nil {
-				http.Error(w, "Failed to create session", http.StatusInternalServerError)
+				utils.HandleErr(w, r, err, "Failed to create session", http.StatusInternalServerError)
 				return
 			}
 
@@ -576,6 +637,7 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 				HttpOnly: true,
 			})
 
+			metrics.Login("google", true)
 			http.Redirect(w, r, "/myprofil", http.StatusSeeOther)
 			return
 
@@ -586,34 +648,18 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 			err := datahandlers.DB.QueryRow("SELECT id, password FROM users WHERE email = ?", email).Scan(&id, &hashedPassword)
 			if err != nil {
 				if err == sql.ErrNoRows {
-					tmplData.Error = "Geçersiz e-posta veya şifre"
+					metrics.Login("password", false)
+					loginError(w, r, "Geçersiz e-posta veya şifre")
 				} else {
-					utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-				}
-				tmpl, err := template.ParseFiles("templates/login.html")
-				if err != nil {
-					utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-					return
-				}
-				err = tmpl.Execute(w, tmplData)
-				if err != nil {
-					utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+					utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 				}
 				return
 			}
 
 			err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 			if err != nil {
-				tmplData.Error = "Geçersiz e-posta veya şifre"
-				tmpl, err := template.ParseFiles("templates/login.html")
-				if err != nil {
-					utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-					return
-				}
-				err = tmpl.Execute(w, tmplData)
-				if err != nil {
-					utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-				}
+				metrics.Login("password", false)
+				loginError(w, r, "Geçersiz e-posta veya şifre")
 				return
 			}
 
@@ -622,7 +668,7 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 			_, err = datahandlers.DB.Exec("INSERT INTO sessions (id, user_id, expiry) VALUES (?, ?, ?)", sessionToken, id, expiresAt)
 			if err != nil {
-				utils.HandleErr(w, err, "Session creation failed", http.StatusInternalServerError)
+				utils.HandleErr(w, r, err, "Session creation failed", http.StatusInternalServerError)
 				return
 			}
 
@@ -634,27 +680,14 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 				Secure:   true,
 			})
 
+			metrics.Login("password", true)
+			flash.AddSuccess(w, r, "Hoş geldiniz! Giriş yaptığınız için teşekkür ederiz.")
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
 		}
 	}
 
-	// Query parametrelerinden hata mesajını al
-	if errorMessage := r.URL.Query().Get("error"); errorMessage != "" {
-		tmplData.Error = errorMessage
-	}
-
-	tmpl, err := template.ParseFiles("templates/login.html")
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	// Hata mesajını şablona geçirerek render et
-	err = tmpl.Execute(w, tmplData)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-	}
+	renderLoginTemplate(w, r)
 }
 
 // Kullanıcının oturumunu kapatır.
@@ -665,14 +698,14 @@ func LogoutHandler(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
 		}
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	sessionToken := cookie.Value
 	_, err = datahandlers.DB.Exec("DELETE FROM sessions WHERE id = ?", sessionToken)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
@@ -682,6 +715,7 @@ func LogoutHandler(w http.ResponseWriter, r *http.Request) {
 		Expires: time.Now().Add(-1 * time.Second),
 	})
 
+	flash.AddInfo(w, r, "You have been logged out.")
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
@@ -698,13 +732,14 @@ func HandleGitHubCallback(w http.ResponseWriter, r *http.Request) {
 	code := r.URL.Query().Get("code")
 	token, err := githubOauthConfig.Exchange(r.Context(), code)
 	if err != nil {
-		http.Error(w, "Failed to exchange token", http.StatusInternalServerError)
+		metrics.Login("github", false)
+		utils.HandleErr(w, r, err, "Failed to exchange token", http.StatusInternalServerError)
 		return
 	}
 
 	email, name, err := getEmailAndNameFromGitHub(token)
 	if err != nil {
-		http.Error(w, "Failed to get user info from GitHub", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Failed to get user info from GitHub", http.StatusInternalServerError)
 		return
 	}
 	// Kullanıcı adını oluştur ve kullanıcıyı kaydet veya mevcut kullanıcıyı getir
@@ -714,20 +749,7 @@ func HandleGitHubCallback(w http.ResponseWriter, r *http.Request) {
 		// Kayıt işlemi
 		user, _ := getUserByEmail(email)
 		if user != nil {
-			tmpl, err := template.ParseFiles("templates/register.html")
-			if err != nil {
-				utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-				return
-			}
-
-			data := RegisterTemplateData{Email: email}
-			data.ErrorMessages = map[string]string{"Email": "Bu Email zaten kayıtlı."}
-
-			// Şablonu işleyerek yanıtı gönder
-			err = tmpl.Execute(w, data)
-			if err != nil {
-				utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-			}
+			registerError(w, r, "Bu Email zaten kayıtlı.")
 		} else {
 			userId, _ := getOrCreateUser(email, username)
 
@@ -750,12 +772,13 @@ func HandleGitHubCallback(w http.ResponseWriter, r *http.Request) {
 		var userID int
 		err = datahandlers.DB.QueryRow("SELECT id FROM users WHERE email = ?", email).Scan(&userID)
 		if err != nil {
+			metrics.Login("github", false)
 			if err == sql.ErrNoRows {
 				// Kullanıcı bulunamadı, hata mesajı göster
-				utils.HandleErr(w, err, "Kullanıcı bulunamadı. Lütfen önce kaydolun.", http.StatusUnauthorized)
+				loginError(w, r, "Kullanıcı bulunamadı. Lütfen önce kaydolun.")
 			} else {
 				// Veritabanı hatası
-				utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+				utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 			}
 			return
 		}
@@ -763,7 +786,7 @@ func HandleGitHubCallback(w http.ResponseWriter, r *http.Request) {
 		// Oturum oluştur
 		sessionToken, err := createSession(int64(userID))
 		if err != nil {
-			http.Error(w, "Oturum oluşturulamadı.", http.StatusInternalServerError)
+			utils.HandleErr(w, r, err, "Oturum oluşturulamadı.", http.StatusInternalServerError)
 			return
 		}
 
@@ -776,6 +799,7 @@ func HandleGitHubCallback(w http.ResponseWriter, r *http.Request) {
 		})
 
 		// Oturum açma başarılı, kullanıcıyı ana sayfaya yönlendir
+		metrics.Login("github", true)
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 	}
 }
@@ -837,14 +861,15 @@ func HandleGoogleCallback(w http.ResponseWriter, r *http.Request) {
 	// Yetkilendirme kodunu access token ile değiştir
 	token, err := googleOauthConfig.Exchange(r.Context(), code)
 	if err != nil {
-		http.Error(w, "Token değişimi başarısız oldu.", http.StatusInternalServerError)
+		metrics.Login("google", false)
+		utils.HandleErr(w, r, err, "Token değişimi başarısız oldu.", http.StatusInternalServerError)
 		return
 	}
 
 	// Access token ile Google'dan kullanıcı bilgilerini al
 	email, name, err := getEmailAndNameFromGoogle(token)
 	if err != nil {
-		http.Error(w, "Google'dan kullanıcı bilgileri alınamadı.", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Google'dan kullanıcı bilgileri alınamadı.", http.StatusInternalServerError)
 		return
 	}
 
@@ -855,20 +880,7 @@ func HandleGoogleCallback(w http.ResponseWriter, r *http.Request) {
 		// Kayıt işlemi
 		user, _ := getUserByEmail(email)
 		if user != nil {
-			tmpl, err := template.ParseFiles("templates/register.html")
-			if err != nil {
-				utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-				return
-			}
-
-			data := RegisterTemplateData{Email: email}
-			data.ErrorMessages = map[string]string{"Email": "Bu Email zaten kayıtlı."}
-
-			// Şablonu işleyerek yanıtı gönder
-			err = tmpl.Execute(w, data)
-			if err != nil {
-				utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-			}
+			registerError(w, r, "Bu Email zaten kayıtlı.")
 		} else {
 			userId, _ := getOrCreateUser(email, username)
 
@@ -891,12 +903,13 @@ func HandleGoogleCallback(w http.ResponseWriter, r *http.Request) {
 		var userID int
 		err = datahandlers.DB.QueryRow("SELECT id FROM users WHERE email = ?", email).Scan(&userID)
 		if err != nil {
+			metrics.Login("google", false)
 			if err == sql.ErrNoRows {
 				// Kullanıcı bulunamadı, hata mesajı göster
-				utils.HandleErr(w, err, "Kullanıcı bulunamadı. Lütfen önce kaydolun.", http.StatusUnauthorized)
+				loginError(w, r, "Kullanıcı bulunamadı. Lütfen önce kaydolun.")
 			} else {
 				// Veritabanı hatası
-				utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+				utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 			}
 			return
 		}
@@ -904,7 +917,7 @@ func HandleGoogleCallback(w http.ResponseWriter, r *http.Request) {
 		// Oturum oluştur
 		sessionToken, err := createSession(int64(userID))
 		if err != nil {
-			http.Error(w, "Oturum oluşturulamadı.", http.StatusInternalServerError)
+			utils.HandleErr(w, r, err, "Oturum oluşturulamadı.", http.StatusInternalServerError)
 			return
 		}
 
@@ -917,6 +930,7 @@ func HandleGoogleCallback(w http.ResponseWriter, r *http.Request) {
 		})
 
 		// Oturum açma başarılı, kullanıcıyı ana sayfaya yönlendir
+		metrics.Login("google", true)
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 	}
 }
@@ -956,7 +970,7 @@ func getOrCreateUser(email, username string) (int64, error) {
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// If user doesn't exist, create a new one
-			res, err := datahandlers.DB.Exec("INSERT INTO users (email, username) VALUES (?, ?)", email, username)
+			res, err := datahandlers.DB.Exec("INSERT INTO users (email, username, created_at) VALUES (?, ?, ?)", email, username, time.Now())
 			if err != nil {
 				return 0, err
 			}
@@ -985,15 +999,8 @@ func createSession(userID int64) (string, error) {
 }
 
 func SifreUnutHandler(w http.ResponseWriter, r *http.Request) {
-	tmpl, err := template.ParseFiles("templates/sifreunut.html")
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	err = tmpl.Execute(w, nil)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+	if err := render.HTML(w, http.StatusOK, "sifreunut", render.NewPage(w, r)); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
@@ -1006,13 +1013,14 @@ func HandleFacebookCallback(w http.ResponseWriter, r *http.Request) {
 	code := r.URL.Query().Get("code")
 	token, err := facebookOauthConfig.Exchange(r.Context(), code)
 	if err != nil {
-		http.Error(w, "Failed to exchange token", http.StatusInternalServerError)
+		metrics.Login("facebook", false)
+		utils.HandleErr(w, r, err, "Failed to exchange token", http.StatusInternalServerError)
 		return
 	}
 
 	email, name, err := getEmailAndNameFromFacebook(token)
 	if err != nil {
-		http.Error(w, "Failed to get user info from Facebook", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Failed to get user info from Facebook", http.StatusInternalServerError)
 		return
 	}
 
@@ -1020,13 +1028,14 @@ func HandleFacebookCallback(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := getOrCreateUser(email, username)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to save user info: %v", err), http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Failed to save user info", http.StatusInternalServerError)
 		return
 	}
 
 	sessionToken, err := createSession(userID)
 	if err != nil {
-		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		metrics.Login("facebook", false)
+		utils.HandleErr(w, r, err, "Failed to create session", http.StatusInternalServerError)
 		return
 	}
 
@@ -1037,6 +1046,7 @@ func HandleFacebookCallback(w http.ResponseWriter, r *http.Request) {
 		HttpOnly: true,
 	})
 
+	metrics.Login("facebook", true)
 	http.Redirect(w, r, "/myprofil", http.StatusTemporaryRedirect)
 }
 
@@ -1060,51 +1070,36 @@ func getEmailAndNameFromFacebook(token *oauth2.Token) (string, string, error) {
 	return userInfo.Email, userInfo.Name, nil
 }
 
-const maxUploadSize = 20 * 1024 * 1024 // 20 MB
-
 func UploadHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.HandleErr(w, r, nil, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-
-	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
-	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
-		http.Error(w, "The uploaded file is too big. Please choose an file that's less than 20MB in size", http.StatusBadRequest)
+	// Oturumsuz yüklemeler kotaya tabi olmadığından kabul edilmez; gövde okunmadan reddedilir
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		utils.WriteError(w, r, utils.Unauthorized("You need to log in to upload files"))
 		return
 	}
 
-	file, handler, err := r.FormFile("file")
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	r.Body = http.MaxBytesReader(w, r.Body, media.MaxFileSize())
+	if err := r.ParseMultipartForm(media.MaxFileSize()); err != nil {
+		utils.HandleErr(w, r, err, fmt.Sprintf("The uploaded file is too big. Please choose a file that's less than %s in size",
+			datahandlers.FormatSize(media.MaxFileSize())), http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
 
-	// Dosya tipi kontrolü
-	allowedExtensions := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".gif":  true,
-	}
-	ext := filepath.Ext(handler.Filename)
-	if !allowedExtensions[ext] {
-		http.Error(w, "The provided file format is not allowed. Please upload a JPEG, PNG, or GIF image", http.StatusBadRequest)
+	attachment, err := media.FromForm(r, "file", "file", session.UserID)
+	if err != nil {
+		utils.WriteError(w, r, err)
 		return
 	}
-
-	// Dosyayı kaydet
-	f, err := os.OpenFile("./uploads/"+handler.Filename, os.O_WRONLY|os.O_CREATE, 0o666)
-	if err != nil {
-		http.Error(w, "Internal Server Error: Dosya kaydedilemedi.", http.StatusInternalServerError)
-		log.Println("Error saving file:", err) // Loglara hata mesajını yaz
+	if attachment == nil {
+		utils.HandleErr(w, r, nil, "Invalid file upload", http.StatusBadRequest)
 		return
 	}
-	defer f.Close()
-	io.Copy(f, file)
 
-	fmt.Fprintf(w, "File uploaded successfully: %s", handler.Filename)
+	fmt.Fprintf(w, "File uploaded successfully: %s", attachment.Filename)
 }
 
 func AdminHandler(w http.ResponseWriter, r *http.Request) {
@@ -1116,48 +1111,51 @@ func AdminHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Check if the user is an admin
 	isAdmin, err := CheckIfAdmin(int64(session.UserID)) // int to int64 conversion
-	if err != nil || !isAdmin {
-		http.Redirect(w, r, "/", http.StatusForbidden)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	if !isAdmin {
+		utils.WriteError(w, r, utils.Forbidden("Only admins can access the admin panel"))
 		return
 	}
 
 	// Fetch users, posts, and categories from the database
 	users, err := getUsers()
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	posts, err := getPosts()
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	categories, err := getCategories()
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	// Render the admin page template
-	tmpl, err := template.ParseFiles("templates/admin.html")
+	roleQuotas, err := media.RoleQuotas()
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	// Render the admin page template
 	data := AdminTemplateData{
+		Page:       render.NewPage(w, r),
 		Users:      users,
 		Posts:      posts,
 		Categories: categories, // Pass categories to the template
-		LoggedIn:   session != nil,
-		IsAdmin:    isAdmin,
+		RoleQuotas: roleQuotas,
 	}
 
-	err = tmpl.Execute(w, data)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+	if err := render.HTML(w, http.StatusOK, "admin", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
@@ -1165,29 +1163,87 @@ func AdminHandler(w http.ResponseWriter, r *http.Request) {
 
 func AddCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		utils.HandleErr(w, r, nil, "Invalid request method", http.StatusMethodNotAllowed)
 		return
 	}
 
 	categoryName := r.FormValue("category_name")
 	if categoryName == "" {
-		http.Error(w, "Category name is required", http.StatusBadRequest)
+		utils.HandleErr(w, r, nil, "Category name is required", http.StatusBadRequest)
 		return
 	}
 
 	_, err := datahandlers.DB.Exec("INSERT INTO categories (name) VALUES (?)", categoryName)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	flash.AddSuccess(w, r, fmt.Sprintf("Category %q added.", categoryName))
 	http.Redirect(w, r, "/admin", http.StatusSeeOther)
 }
 
+// Admin panelinden bir rolün yükleme kotasını ayarlar; boş değer yapılandırmadaki kotaya döndürür.
+func UpdateRoleQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	role := strings.TrimSpace(r.FormValue("role"))
+	if role == "" {
+		utils.WriteError(w, r, utils.BadRequest("Role is required", nil))
+		return
+	}
+	quota, err := parseQuotaMB(r.FormValue("quota_mb"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+	if err := datahandlers.SetRoleQuota(role, quota); err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+
+	flash.AddSuccess(w, r, fmt.Sprintf("Upload quota for role %q updated.", role))
+	http.Redirect(w, r, "/admin#upload-quotas", http.StatusSeeOther)
+}
+
+// Formdaki MB cinsinden kotayı bayta çevirir. Boş değer kotanın kaldırıldığını (üst
+// düzeydeki kotanın geçerli olduğunu), 0 sınırsız kotayı belirtir.
+func parseQuotaMB(value string) (sql.NullInt64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return sql.NullInt64{}, nil
+	}
+	mb, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || mb < 0 || mb > 1<<20 {
+		return sql.NullInt64{}, utils.BadRequest("Quota must be a whole number of megabytes (0 for unlimited)", err)
+	}
+	return sql.NullInt64{Int64: mb << 20, Valid: true}, nil
+}
+
+// Oturumdaki kullanıcının admin olduğunu doğrular; değilse hatayı yazar ve false döner.
+func requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	user, err := datahandlers.GetSessionUser(r)
+	if err != nil || user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return false
+	}
+	if !user.IsAdmin() {
+		utils.WriteError(w, r, utils.Forbidden("Only admins can do this"))
+		return false
+	}
+	return true
+}
+
 func DeleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	// Sadece POST metodu izin ver
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.HandleErr(w, r, nil, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -1200,26 +1256,31 @@ func DeleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Kullanıcının admin veya moderator olup olmadığını kontrol et
 	isAdmin, err := CheckIfAdmin(int64(session.UserID))
-	if err != nil || !isAdmin {
-		http.Redirect(w, r, "/", http.StatusForbidden)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	if !isAdmin {
+		utils.WriteError(w, r, utils.Forbidden("Only admins can delete categories"))
 		return
 	}
 
 	// Category ID'yi al
 	categoryID := strings.TrimPrefix(r.URL.Path, "/categories/delete/")
 	if categoryID == "" {
-		http.Error(w, "Category ID is required", http.StatusBadRequest)
+		utils.HandleErr(w, r, nil, "Category ID is required", http.StatusBadRequest)
 		return
 	}
 
 	// SQL sorgusu ile kategoriyi sil
 	_, err = datahandlers.DB.Exec("DELETE FROM categories WHERE id = ?", categoryID)
 	if err != nil {
-		utils.HandleErr(w, err, "Failed to delete category", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Failed to delete category", http.StatusInternalServerError)
 		return
 	}
 
 	// Başarı durumunda admin sayfasına yönlendir
+	flash.AddSuccess(w, r, "Category deleted.")
 	http.Redirect(w, r, "/admin", http.StatusSeeOther)
 }
 
@@ -1259,24 +1320,43 @@ func CheckIfAdmin(userID int64) (bool, error) {
 }
 
 func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	isAdmin, err := CheckIfAdmin(int64(session.UserID))
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	if !isAdmin {
+		utils.WriteError(w, r, utils.Forbidden("Only admins can delete users"))
+		return
+	}
+
 	userIDStr := strings.TrimPrefix(r.URL.Path, "/users/delete/")
 	userID, err := strconv.Atoi(userIDStr)
 	if err != nil {
-		utils.HandleErr(w, err, "Invalid user ID", http.StatusBadRequest)
+		utils.HandleErr(w, r, err, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
 
 	// Kullanıcı bilgilerini alın
 	user, err := getUserByID(userID)
 	if err != nil {
-		utils.HandleErr(w, err, "User not found", http.StatusNotFound)
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	// Transaction başlat
 	tx, err := datahandlers.DB.Begin()
 	if err != nil {
-		utils.HandleErr(w, err, "Failed to begin transaction", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Failed to begin transaction", http.StatusInternalServerError)
 		return
 	}
 
@@ -1287,11 +1367,21 @@ func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	// Yabancı anahtar kuralı olmayan oturumları ve şikayetleri sil; diğer kayıtları
+	// (takipler, engellemeler, bildirimler vb.) veritabanı kullanıcıyla birlikte siler
+	for _, query := range []string{"DELETE FROM sessions WHERE user_id = ?", "DELETE FROM reports WHERE user_id = ?"} {
+		if _, err := tx.Exec(query, userID); err != nil {
+			tx.Rollback()
+			utils.HandleErr(w, r, err, "Failed to delete user", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// Kullanıcıyı users tablosundan sil
 	_, err = tx.Exec("DELETE FROM users WHERE id = ?", userID)
 	if err != nil {
 		tx.Rollback()
-		utils.HandleErr(w, err, "Failed to delete user", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Failed to delete user", http.StatusInternalServerError)
 		return
 	}
 
@@ -1299,17 +1389,18 @@ func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 	_, err = tx.Exec("INSERT INTO banned_users (email) VALUES (?)", user.Email)
 	if err != nil {
 		tx.Rollback()
-		utils.HandleErr(w, err, "Failed to ban user", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Failed to ban user", http.StatusInternalServerError)
 		return
 	}
 
 	// Transaction'ı commit et
 	err = tx.Commit()
 	if err != nil {
-		utils.HandleErr(w, err, "Failed to commit transaction", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Failed to commit transaction", http.StatusInternalServerError)
 		return
 	}
 
+	flash.AddSuccess(w, r, fmt.Sprintf("User %s deleted and banned.", user.Email))
 	http.Redirect(w, r, "/admin", http.StatusSeeOther)
 }
 
@@ -1321,18 +1412,35 @@ func checkIfBanned(email string) (bool, error) {
 
 // Kullanıcı bilgilerini güncelleyen handler
 func UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
-	userID := strings.TrimPrefix(r.URL.Path, "/users/update/")
+	if !requireAdmin(w, r) {
+		return
+	}
+	userID, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/users/update/"))
+	if err != nil {
+		utils.HandleErr(w, r, err, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
 
 	email := r.FormValue("email")
 	username := r.FormValue("username")
 	role := r.FormValue("role")
+	quota, err := parseQuotaMB(r.FormValue("upload_quota_mb"))
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
 
-	_, err := datahandlers.DB.Exec("UPDATE users SET email = ?, username = ?, role = ? WHERE id = ?", email, username, role, userID)
+	_, err = datahandlers.DB.Exec("UPDATE users SET email = ?, username = ?, role = ? WHERE id = ?", email, username, role, userID)
 	if err != nil {
-		utils.HandleErr(w, err, "Failed to update user", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Failed to update user", http.StatusInternalServerError)
+		return
+	}
+	if err := datahandlers.SetUserQuota(userID, quota); err != nil {
+		utils.HandleErr(w, r, err, "Failed to update user", http.StatusInternalServerError)
 		return
 	}
 
+	flash.AddSuccess(w, r, "User updated.")
 	http.Redirect(w, r, "/admin", http.StatusSeeOther)
 }
 
@@ -1359,31 +1467,26 @@ func EditUserHandler(w http.ResponseWriter, r *http.Request) {
 	userIDStr := strings.TrimPrefix(r.URL.Path, "/users/edit/")
 	userID, err := strconv.Atoi(userIDStr)
 	if err != nil {
-		utils.HandleErr(w, err, "Invalid user ID", http.StatusBadRequest)
+		utils.HandleErr(w, r, err, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
 
 	user, err := getUserByID(userID)
 	if err != nil {
-		utils.HandleErr(w, err, err.Error(), http.StatusNotFound)
-		return
-	}
-
-	tmpl, err := template.ParseFiles("templates/edit_user.html")
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.WriteError(w, r, err)
 		return
 	}
 
 	data := struct {
+		render.Page
 		User *User
 	}{
+		Page: render.NewPage(w, r),
 		User: user,
 	}
 
-	err = tmpl.Execute(w, data)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+	if err := render.HTML(w, http.StatusOK, "edit_user", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
@@ -1394,7 +1497,7 @@ func getUserByID(userID int) (*User, error) {
 	err := datahandlers.DB.QueryRow(query, userID).Scan(&user.ID, &user.Email, &user.Username, &user.Role)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user with ID %d not found", userID)
+			return nil, utils.NotFound(fmt.Sprintf("user with ID %d not found", userID))
 		}
 		return nil, err
 	}
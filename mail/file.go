@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"form-project/config"
+)
+
+// fileMailer, e-postaları göndermek yerine dizine .eml dosyaları olarak yazar; yerel
+// geliştirme ve test içindir. Dosyalar herhangi bir e-posta istemcisiyle açılabilir.
+type fileMailer struct {
+	dir  string
+	from string
+	seq  atomic.Int64
+}
+
+func newFile(cfg config.Mail) (*fileMailer, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create mail dir %s: %w", cfg.Dir, err)
+	}
+	return &fileMailer{dir: cfg.Dir, from: cfg.From}, nil
+}
+
+func (m *fileMailer) Name() string {
+	return "file"
+}
+
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := format(m.from, msg)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%03d.eml", time.Now().Format("20060102-150405.000"), m.seq.Add(1)%1000)
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o644)
+}
@@ -0,0 +1,107 @@
+package mail // Bildirim e-postalarını SMTP ile veya yerel test için dosyaya yazarak gönderen paket
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"form-project/config"
+)
+
+// Message, düz metin bir e-postadır. Headers ek başlıklardır (ör. List-Unsubscribe).
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	Headers map[string]string
+}
+
+// Mailer, e-posta gönderen bir arka uçtur.
+type Mailer interface {
+	Send(ctx context.Context, m Message) error
+	Name() string
+}
+
+// New, yapılandırmadaki sürücü için bir Mailer oluşturur; sürücü boşsa nil döner
+// (e-posta gönderimi kapalı).
+func New(cfg config.Mail) (Mailer, error) {
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("invalid mail.from %q: %w", cfg.From, err)
+	}
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case "smtp":
+		return newSMTP(cfg), nil
+	case "file":
+		return newFile(cfg)
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// E-postayı RFC 5322 biçiminde kodlar; gövde quoted-printable UTF-8 metindir.
+func format(from string, m Message) ([]byte, error) {
+	var buf bytes.Buffer
+	headers := map[string]string{
+		"From":                      from,
+		"To":                        m.To,
+		"Subject":                   mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date":                      time.Now().Format(time.RFC1123Z),
+		"Message-ID":                messageID(from),
+		"MIME-Version":              "1.0",
+		"Content-Type":              "text/plain; charset=utf-8",
+		"Content-Transfer-Encoding": "quoted-printable",
+	}
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		// Başlık enjeksiyonuna karşı satır sonları atılır
+		v := strings.NewReplacer("\r", "", "\n", "").Replace(headers[k])
+		fmt.Fprintf(&buf, "%s: %s\r\n", k, v)
+	}
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(m.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndexByte(addr.Address, '@'); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
+
+// Zarf için gönderen adresinin yalnızca e-posta kısmı
+func envelopeAddress(address string) string {
+	if addr, err := mail.ParseAddress(address); err == nil {
+		return addr.Address
+	}
+	return address
+}
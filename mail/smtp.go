@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+
+	"form-project/config"
+)
+
+// smtpMailer, e-postaları bir SMTP sunucusu üzerinden gönderir.
+type smtpMailer struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from string
+}
+
+func newSMTP(cfg config.Mail) *smtpMailer {
+	m := &smtpMailer{
+		addr: net.JoinHostPort(cfg.SMTP.Host, strconv.Itoa(cfg.SMTP.Port)),
+		host: cfg.SMTP.Host,
+		from: cfg.From,
+	}
+	if cfg.SMTP.Username != "" {
+		m.auth = smtp.PlainAuth("", cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.Host)
+	}
+	return m
+}
+
+func (m *smtpMailer) Name() string {
+	return "smtp"
+}
+
+// Send, e-postayı gönderir. smtp.SendMail sunucu destekliyorsa STARTTLS'e geçer.
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := format(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(m.addr, m.auth, envelopeAddress(m.from), []string{envelopeAddress(msg.To)}, data); err != nil {
+		return fmt.Errorf("send mail to %s via %s: %w", msg.To, m.addr, err)
+	}
+	return nil
+}
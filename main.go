@@ -1,23 +1,302 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"form-project/allhandlers"
+	"form-project/badges"
+	"form-project/config"
 	"form-project/datahandlers" // Veritabanı bağlantı bilgileri
+	"form-project/healthhandlers"
+	"form-project/mail"
+	"form-project/media"
+	"form-project/notifications"
+	"form-project/render"
+	"form-project/reputation"
+	"form-project/security"
+	"form-project/storage"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+const addr = ":8065"
+
 func main() {
+	healthcheck := flag.Bool("healthcheck", false, "çalışan sunucunun sağlık durumunu kontrol et ve çık (Docker HEALTHCHECK için)")
+	healthcheckPath := flag.String("healthcheck-path", "/readyz", "-healthcheck ile kontrol edilecek uç nokta")
+	dev := flag.Bool("dev", false, "geliştirme modu: şablonları her istekte diskten yeniden yükle")
+	gcInterval := flag.Duration("gc-interval", time.Hour, "sahipsiz yüklemeleri temizleme aralığı (0 kapatır)")
+	gcGrace := flag.Duration("gc-grace", 24*time.Hour, "sahipsiz bir yüklemenin silinmeden önce bekleyeceği süre")
+	emailInterval := flag.Duration("email-interval", time.Minute, "bildirim e-postalarını gönderme aralığı (0 kapatır)")
+	flag.Parse()
+
+	// HEALTHCHECK modu: sunucuyu başlatmadan çalışan örneği yoklar, 0 veya 1 ile çıkar.
+	if *healthcheck {
+		if err := healthhandlers.Probe("http://127.0.0.1" + addr + *healthcheckPath); err != nil {
+			fmt.Fprintln(os.Stderr, "unhealthy:", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	cfg, err := config.Load(config.DefaultPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Yüklemeler yapılandırmadaki depoda (yerel dizin veya S3) saklanır.
+	store, err := storage.New(cfg.Storage, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	media.SetStore(store)
+	media.SetLimits(cfg.Uploads)
+	reputation.SetConfig(cfg.Reputation)
+
+	// Bildirim e-postaları yapılandırmadaki sürücüyle (SMTP veya dosya) gönderilir; sürücü yoksa kapalıdır.
+	mailer, err := mail.New(cfg.Mail)
+	if err != nil {
+		log.Fatal(err)
+	}
+	notifications.SetMailer(mailer, cfg.Mail.BaseURL)
+
+	// Yönetim komutları: sunucuyu başlatmadan çalışır ve çıkar.
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(cfg, flag.Arg(0), flag.Args()[1:]))
+	}
+
 	datahandlers.SetDB() // Bu fonksiyon, veritabanı dosyasının yolunu ve diğer gerekli ayarları alarak bağlantıyı başlatır.
 	defer datahandlers.DB.Close()
 
 	datahandlers.CreateTables() // fonksiyonu ile veritabanında gerekli tablolar (örneğin, kullanıcı bilgileri, form verileri) oluşturulur.
 
+	// Puanlar oylarla artımlı güncellenir; ilk açılışta ve ağırlıklar değiştiğinde baştan hesaplanır.
+	if recalculated, err := reputation.Sync(); err != nil {
+		log.Fatal(err)
+	} else if recalculated {
+		log.Println("Reputation recalculated with the configured weights")
+	}
+
+	// Flash gibi imzalı çerezlerin anahtarı veritabanında saklanır; yeniden başlatmada geçersiz olmaz.
+	signingKey, err := datahandlers.Secret("cookie_signing_key", 32)
+	if err != nil {
+		log.Fatal(err)
+	}
+	security.SetSigningKey(signingKey)
+
+	// Şablonlar başlangıçta bir kez ayrıştırılır; hatalı bir şablon sunucunun başlamasını engeller.
+	render.AddFuncs(media.TemplateFuncs())
+	if err := render.Load("templates", *dev); err != nil {
+		log.Fatal(err)
+	}
+
+	// Silinen gönderi/yorumların ve hiç kullanılmayan yüklemelerin dosyaları arka planda temizlenir.
+	media.StartGC(*gcInterval, *gcGrace)
+	// Anlık e-postalar ve günlük/haftalık özetler arka planda toplu gönderilir.
+	notifications.StartEmailDelivery(*emailInterval)
+
 	allhandlers.Allhandlers() //  fonksiyonu, HTTP isteklerini karşılayacak işleyicileri (handler) tanımlar ve kaydeder.
 	// Bu işleyiciler, /form, /submit gibi farklı URL yollarına gelen istekleri ele alır.
 
-	log.Println("Server started at :8065")
-	http.ListenAndServe(":8065", nil)
+	mailDriver := "disabled"
+	if mailer != nil {
+		mailDriver = mailer.Name()
+	}
+	log.Printf("Server started at %s (uploads: %s storage, email: %s)", addr, store.Name(), mailDriver)
+	log.Fatal(http.ListenAndServe(addr, nil))
+}
+
+// Komut satırı alt komutlarını çalıştırır ve çıkış kodunu döndürür.
+func runCommand(cfg *config.Config, name string, args []string) int {
+	switch name {
+	case "backfill-variants":
+		// Varyantlar eklenmeden önce yüklenmiş görseller için küçük resim ve orta boy dosyaları üretir.
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "dosya yazmadan üretilecek varyantları say")
+		fs.Parse(args)
+
+		report, err := media.Backfill(context.Background(), *dryRun)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "backfill-variants:", err)
+			return 1
+		}
+		verb := "generated"
+		if *dryRun {
+			verb = "would generate"
+		}
+		fmt.Printf("scanned %d images, %s %d variants, %d failed\n", report.Scanned, verb, report.Generated, len(report.Failed))
+		for _, failure := range report.Failed {
+			fmt.Println("  failed:", failure)
+		}
+		if len(report.Failed) > 0 {
+			return 1
+		}
+		return 0
+	case "migrate-uploads":
+		// Yüklemeleri bir depodan diğerine (ör. yerel dizinden S3'e) kopyalar.
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		from := fs.String("from", "local", "kaynak depo (local veya s3)")
+		to := fs.String("to", "s3", "hedef depo (local veya s3)")
+		dryRun := fs.Bool("dry-run", false, "kopyalamadan taşınacak dosyaları say")
+		deleteSource := fs.Bool("delete-source", false, "kopyalanan dosyaları kaynaktan sil")
+		fs.Parse(args)
+
+		if *from == *to {
+			fmt.Fprintln(os.Stderr, "migrate-uploads: -from and -to must differ")
+			return 2
+		}
+		src, err := storage.New(cfg.Storage, *from)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate-uploads:", err)
+			return 1
+		}
+		dst, err := storage.New(cfg.Storage, *to)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate-uploads:", err)
+			return 1
+		}
+
+		report, err := storage.Migrate(context.Background(), src, dst, *dryRun, *deleteSource)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "migrate-uploads:", err)
+			return 1
+		}
+		verb := "copied"
+		if *dryRun {
+			verb = "would copy"
+		}
+		fmt.Printf("%s -> %s: scanned %d files, %s %d, skipped %d already present, deleted %d, %d failed\n",
+			src.Name(), dst.Name(), report.Scanned, verb, report.Copied, report.Skipped, report.Deleted, len(report.Failed))
+		for _, failure := range report.Failed {
+			fmt.Println("  failed:", failure)
+		}
+		if len(report.Failed) > 0 {
+			return 1
+		}
+		return 0
+	case "gc-uploads":
+		// Hiçbir gönderi, yorum veya profilin kullanmadığı yüklemeleri bekleme süresinden sonra siler.
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		grace := fs.Duration("grace", 24*time.Hour, "sahipsiz bir yüklemenin silinmeden önce bekleyeceği süre")
+		dryRun := fs.Bool("dry-run", false, "hiçbir şeyi değiştirmeden silinecek dosyaları listele")
+		fs.Parse(args)
+
+		datahandlers.SetDB()
+		defer datahandlers.DB.Close()
+
+		report, err := media.CollectGarbage(context.Background(), *grace, *dryRun)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "gc-uploads:", err)
+			return 1
+		}
+		verb := "deleted"
+		if *dryRun {
+			verb = "would delete"
+		}
+		fmt.Printf("marked %d newly orphaned, %s %d orphaned attachments and %d untracked files, %d pending grace period, %d failed\n",
+			report.Marked, verb, len(report.Deleted), len(report.Untracked), len(report.Pending), len(report.Failed))
+		for _, key := range report.Deleted {
+			fmt.Println("  orphaned: ", key)
+		}
+		for _, key := range report.Untracked {
+			fmt.Println("  untracked:", key)
+		}
+		for _, key := range report.Pending {
+			fmt.Println("  pending:  ", key)
+		}
+		for _, failure := range report.Failed {
+			fmt.Println("  failed:   ", failure)
+		}
+		if len(report.Failed) > 0 {
+			return 1
+		}
+		return 0
+	case "send-emails":
+		// Zamanı gelen bildirim e-postalarını bir kez gönderir (ör. sunucu -email-interval 0 ile çalışırken cron'dan).
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		fs.Parse(args)
+
+		if !notifications.EmailEnabled() {
+			fmt.Fprintln(os.Stderr, "send-emails: mail.driver is not configured")
+			return 2
+		}
+		datahandlers.SetDB()
+		defer datahandlers.DB.Close()
+		// Abonelikten çıkma bağlantıları sunucuyla aynı anahtarla imzalanmalıdır
+		signingKey, err := datahandlers.Secret("cookie_signing_key", 32)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "send-emails:", err)
+			return 1
+		}
+		security.SetSigningKey(signingKey)
+
+		report, err := notifications.SendEmails(context.Background(), time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "send-emails:", err)
+			return 1
+		}
+		fmt.Printf("%d users with pending notifications, sent %d emails with %d notifications, %d failed\n",
+			report.Recipients, report.Sent, report.Notifications, len(report.Failed))
+		for _, failure := range report.Failed {
+			fmt.Println("  failed:", failure)
+		}
+		if len(report.Failed) > 0 {
+			return 1
+		}
+		return 0
+	case "backfill-badges":
+		// Rozetler eklenmeden önceki forum geçmişinden kazanılmış rozetleri verir.
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "rozet vermeden verilecek rozetleri say")
+		fs.Parse(args)
+
+		datahandlers.SetDB()
+		defer datahandlers.DB.Close()
+		report, err := badges.Backfill(time.Now(), *dryRun)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "backfill-badges:", err)
+			return 1
+		}
+		verb := "awarded"
+		if *dryRun {
+			verb = "would award"
+		}
+		total := 0
+		for _, n := range report.Awarded {
+			total += n
+		}
+		fmt.Printf("evaluated %d users, %s %d badges\n", report.Users, verb, total)
+		for _, b := range badges.All {
+			if n := report.Awarded[b.ID]; n > 0 {
+				fmt.Printf("  %-12s %d\n", b.ID, n)
+			}
+		}
+		return 0
+	case "reconcile":
+		// Gönderi ve yorumlardaki oy ve yorum sayaçlarını kaynak tablolardan yeniden hesaplar.
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "sayaçları düzeltmeden tutarsız satırları say")
+		fs.Parse(args)
+
+		datahandlers.SetDB()
+		defer datahandlers.DB.Close()
+		report, err := datahandlers.ReconcileCounters(*dryRun)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "reconcile:", err)
+			return 1
+		}
+		verb := "fixed"
+		if *dryRun {
+			verb = "would fix"
+		}
+		fmt.Printf("%s counters on %d posts and %d comments\n", verb, report.Posts, report.Comments)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q (available: backfill-variants, migrate-uploads, gc-uploads, send-emails, backfill-badges, reconcile)\n", name)
+		return 2
+	}
 }
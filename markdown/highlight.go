@@ -0,0 +1,135 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/util"
+)
+
+// Açık ve koyu tema için kullanılan chroma stilleri; sitenin light-mode/night-mode
+// sınıflarına bağlanır.
+const (
+	lightStyle = "github"
+	darkStyle  = "github-dark"
+)
+
+// codeBlockRenderer, çitli kod bloklarını sunucu tarafında renklendirir. Dil belirtilmemişse
+// veya tanınmıyorsa içerikten tahmin edilir, tahmin edilemezse düz metin olarak gösterilir.
+// Renkler satır içi stil yerine sınıflarla verilir (temizleyici stil özniteliklerine izin vermez).
+type codeBlockRenderer struct{}
+
+func (r codeBlockRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindFencedCodeBlock, r.renderFencedCodeBlock)
+}
+
+func (r codeBlockRenderer) renderFencedCodeBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*ast.FencedCodeBlock)
+
+	var code strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		code.Write(line.Value(source))
+	}
+
+	var lang string
+	if n.Info != nil {
+		lang = string(n.Language(source))
+	}
+	lexer := detectLexer(lang, code.String())
+	name := strings.ReplaceAll(strings.ToLower(lexer.Config().Name), " ", "-")
+
+	// Tek satırlık bloklarda satır numarası gösterilmez
+	formatter := chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.WithLineNumbers(lines.Len() > 1),
+		chromahtml.WithPreWrapper(preWrapper(name)),
+	)
+
+	fmt.Fprintf(w, `<div class="code-block" data-lang="%s">`, template.HTMLEscapeString(lexer.Config().Name))
+	iterator, err := lexer.Tokenise(nil, code.String())
+	if err == nil {
+		err = formatter.Format(w, styles.Get(lightStyle), iterator)
+	}
+	if err != nil {
+		// Renklendirilemeyen blok düz metin olarak yazılır
+		fmt.Fprintf(w, "%s%s%s", preWrapper(name).Start(true, ""), template.HTMLEscapeString(code.String()), preWrapper(name).End(true))
+	}
+	w.WriteString("</div>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// Belirtilen dilin, yoksa içerikten tahmin edilen dilin sözcük çözümleyicisini döndürür.
+func detectLexer(lang, code string) chroma.Lexer {
+	var lexer chroma.Lexer
+	if lang != "" {
+		lexer = lexers.Get(lang)
+	}
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Get("plaintext")
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// <pre class="chroma"><code class="language-go"> sarmalayıcısı. tabindex gibi temizleyicinin
+// atacağı öznitelikler yazılmaz.
+type preWrapper string
+
+func (p preWrapper) Start(code bool, styleAttr string) string {
+	return `<pre class="chroma"><code class="language-` + template.HTMLEscapeString(string(p)) + `">`
+}
+
+func (p preWrapper) End(code bool) string {
+	return "</code></pre>"
+}
+
+var (
+	stylesheetOnce sync.Once
+	stylesheet     []byte
+)
+
+// Stylesheet, kod renklendirmesinin CSS'ini döndürür: açık tema body.light-mode,
+// koyu tema body.night-mode altında geçerlidir.
+func Stylesheet() []byte {
+	stylesheetOnce.Do(func() {
+		var buf bytes.Buffer
+		formatter := chromahtml.New(chromahtml.WithClasses(true), chromahtml.WithLineNumbers(true))
+		for _, theme := range []struct{ body, style string }{
+			{"body.light-mode", lightStyle},
+			{"body.night-mode", darkStyle},
+		} {
+			var css bytes.Buffer
+			if err := formatter.WriteCSS(&css, styles.Get(theme.style)); err != nil {
+				continue
+			}
+			// Her kural "/* Tür */ .chroma .k { ... }" biçimindedir; seçiciler temanın altına alınır
+			buf.WriteString(strings.ReplaceAll(css.String(), "*/ .", "*/ "+theme.body+" ."))
+		}
+		stylesheet = buf.Bytes()
+	})
+	return stylesheet
+}
+
+// StylesheetHandler, Stylesheet çıktısını sunar.
+func StylesheetHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(Stylesheet())
+}
@@ -0,0 +1,78 @@
+package markdown // Gönderi ve yorum içeriğini Markdown olarak işleyip izin listesiyle temizlenmiş HTML'e çeviren paket
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// Version, işleyicinin sürümüdür. Üretilen HTML'i değiştiren her değişiklikte artırılır;
+// veritabanında daha eski sürümle önbelleğe alınmış içerik okunurken yeniden işlenir.
+const Version = 3
+
+// CommonMark ve GFM eklentileri: tablolar, üstü çizili metin, otomatik bağlantılar ve
+// görev listeleri. Ham HTML işlenmez ("raw HTML omitted" yorumu olarak atlanır); satır
+// sonları önceki düz metin görünümüyle uyumlu olması için <br> olarak korunur. Çitli kod
+// blokları codeBlockRenderer ile renklendirilir, @kullanıcıadı ifadeleri mentionParser ile
+// profil bağlantısına çevrilir.
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithInlineParsers(util.Prioritized(mentionParser{}, 500))),
+	goldmark.WithRendererOptions(
+		html.WithHardWraps(),
+		renderer.WithNodeRenderers(util.Prioritized(codeBlockRenderer{}, 100)),
+	),
+)
+
+// Ham HTML işlenmese de Markdown bağlantı adresleri (ör. javascript:) ve ileride
+// eklenecek eklentiler için çıktı her zaman izin listesinden geçirilir.
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// Çitli kod bloklarının dil sınıfı (```go -> class="language-go")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#.-]+$`)).OnElements("code")
+	// Renklendirilmiş kod blokları: chroma'nın belirteç sınıfları ve dil etiketi
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^code-block$`)).OnElements("div")
+	p.AllowAttrs("data-lang").Matching(regexp.MustCompile(`^[\w+#. -]+$`)).OnElements("div")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^chroma$`)).OnElements("pre")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[a-z0-9]+( [a-z0-9]+)*$`)).OnElements("span")
+	// Kullanıcı bahsetme bağlantıları
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^mention$`)).OnElements("a")
+	// Görev listelerinin salt okunur onay kutuları
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	// Tablo hücre hizalaması
+	p.AllowAttrs("style").OnElements("th", "td")
+	p.AllowStyles("text-align").MatchingEnum("left", "center", "right").OnElements("th", "td")
+
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// Render, Markdown kaynağını güvenli HTML'e çevirir. mentions, var olduğu doğrulanmış
+// kullanıcı adlarıdır; yalnızca bunlardan bahseden @ifadeler profile bağlanır.
+func Render(source string, mentions ...string) template.HTML {
+	known := make(map[string]bool, len(mentions))
+	for _, name := range mentions {
+		known[name] = true
+	}
+	ctx := parser.NewContext()
+	ctx.Set(knownMentionsKey, known)
+
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(source), &buf, parser.WithContext(ctx)); err != nil {
+		// goldmark yalnızca yazma hatasında hata döndürür; içerik düz metin olarak gösterilir
+		return template.HTML("<p>" + template.HTMLEscapeString(source) + "</p>")
+	}
+	return template.HTML(policy.SanitizeBytes(buf.Bytes()))
+}
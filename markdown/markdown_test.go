@@ -0,0 +1,152 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSanitizes(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		mentions []string
+		want     []string // Çıktıda bulunması gerekenler
+		reject   []string // Çıktıda bulunmaması gerekenler
+	}{
+		{
+			name:   "javascript link",
+			source: "[click](javascript:alert(1))",
+			want:   []string{"click"},
+			reject: []string{"javascript:", "href"},
+		},
+		{
+			name:   "javascript autolink",
+			source: "<javascript:alert(1)>",
+			reject: []string{"href=\"javascript:"},
+		},
+		{
+			name:   "raw script",
+			source: "<script>alert(1)</script>",
+			reject: []string{"<script", "alert(1)</script>"},
+		},
+		{
+			name:   "raw img onerror",
+			source: "<img src=x onerror=alert(1)>",
+			reject: []string{"<img", "onerror"},
+		},
+		{
+			name:   "markdown image cannot carry handlers",
+			source: `![x](/a.png "t\" onerror=\"alert(1)")`,
+			want:   []string{"<img"},
+			reject: []string{"onerror="},
+		},
+		{
+			name:   "external link gets nofollow and target",
+			source: "[site](https://example.com)",
+			want:   []string{`href="https://example.com"`, `rel="nofollow noopener"`, `target="_blank"`},
+		},
+		{
+			name:   "table alignment style",
+			source: "| a | b |\n|:-:|--:|\n| 1 | 2 |",
+			want:   []string{`<th style="text-align: center">`, `<td style="text-align: right">`},
+		},
+		{
+			name:   "style on non-table elements",
+			source: `<p style="color:red">x</p><span style="position:fixed">y</span>`,
+			reject: []string{"style="},
+		},
+		{
+			name:   "task list checkboxes",
+			source: "- [x] done\n- [ ] todo",
+			want:   []string{`<input checked="" disabled="" type="checkbox"`, `<input disabled="" type="checkbox"`},
+		},
+		{
+			name:   "raw non-checkbox input",
+			source: `<input type="text" value="x"><input type="checkbox">`,
+			reject: []string{"<input"},
+		},
+		{
+			name:   "code block language and data-lang",
+			source: "```go\nfunc main() {}\n```",
+			want:   []string{`class="code-block"`, `data-lang="Go"`, `<pre class="chroma">`, `<code class="language-go">`},
+		},
+		{
+			name:   "span classes only from highlighter",
+			source: `<span class="x" onclick="alert(1)">y</span>`,
+			reject: []string{"onclick", "<span"},
+		},
+		{
+			name:     "mention of known user",
+			source:   "hello @alice",
+			mentions: []string{"alice"},
+			want:     []string{`<a href="/u/alice" class="mention"`},
+		},
+		{
+			name:   "mention of unknown user",
+			source: "hello @mallory",
+			want:   []string{"@mallory"},
+			reject: []string{"<a", "mention"},
+		},
+		{
+			name:     "mention inside code",
+			source:   "`@alice`",
+			mentions: []string{"alice"},
+			want:     []string{"<code>@alice</code>"},
+			reject:   []string{"/u/alice"},
+		},
+		{
+			name:     "email address is not a mention",
+			source:   "bob@alice.com",
+			mentions: []string{"alice"},
+			reject:   []string{"/u/alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(Render(tt.source, tt.mentions...))
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("Render(%q) = %q, want it to contain %q", tt.source, got, w)
+				}
+			}
+			for _, r := range tt.reject {
+				if strings.Contains(got, r) {
+					t.Errorf("Render(%q) = %q, must not contain %q", tt.source, got, r)
+				}
+			}
+		})
+	}
+}
+
+// Ham HTML goldmark'ta atıldığından izin listesi ayrıca doğrudan denenir
+func TestPolicy(t *testing.T) {
+	tests := []struct{ input, want string }{
+		{`<a href="javascript:alert(1)">x</a>`, `x`},
+		{`<script>alert(1)</script>ok`, `ok`},
+		{`<img src="/a.png" onerror="alert(1)">`, `<img src="/a.png">`},
+		{`<p style="color: red">x</p>`, `<p>x</p>`},
+		{`<td style="text-align: center; color: red">x</td>`, `<td style="text-align: center">x</td>`},
+		{`<th style="text-align: justify">x</th>`, `<th>x</th>`},
+		{`<input type="checkbox" checked disabled>`, `<input type="checkbox" checked="" disabled="">`},
+		{`<input type="text" value="x">`, ``},
+		{`<span class="kd">x</span>`, `<span class="kd">x</span>`},
+		{`<span class="Evil_Class" style="position: fixed">x</span>`, `<span>x</span>`},
+		{`<div class="code-block" data-lang="Go">x</div>`, `<div class="code-block" data-lang="Go">x</div>`},
+		{`<div class="other" data-lang="x&quot;><script>">x</div>`, `<div>x</div>`},
+		{`<a class="mention" href="/u/alice">@alice</a>`, `<a class="mention" href="/u/alice" rel="nofollow">@alice</a>`},
+		{`<a class="button" href="/x">x</a>`, `<a href="/x" rel="nofollow">x</a>`},
+	}
+	for _, tt := range tests {
+		if got := policy.Sanitize(tt.input); got != tt.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestMentions(t *testing.T) {
+	got := Mentions("@alice and @bob, again @alice; `@carol` mail@dave.com")
+	if strings.Join(got, ",") != "alice,bob" {
+		t.Errorf("Mentions = %v, want [alice bob]", got)
+	}
+}
@@ -0,0 +1,95 @@
+package markdown
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// Bir içerikte en fazla bu kadar farklı kullanıcıdan bahsedilebilir
+const MaxMentions = 20
+
+var (
+	// İşlenirken bağlantıya çevrilecek, var olduğu bilinen kullanıcı adları (map[string]bool)
+	knownMentionsKey = parser.NewContextKey()
+	// Mentions çağrısında bulunan tüm adaylar (*[]string)
+	collectMentionsKey = parser.NewContextKey()
+)
+
+// mentionParser, @kullanıcıadı ifadelerini ayrıştırır. Kod içindekiler ve bir kelimenin
+// ortasındakiler (ör. e-posta adresleri) sayılmaz.
+type mentionParser struct{}
+
+func (mentionParser) Trigger() []byte {
+	return []byte{'@'}
+}
+
+func (mentionParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if before := block.PrecendingCharacter(); before == '@' || isNameRune(before) {
+		return nil
+	}
+	line, segment := block.PeekLine()
+	name := mentionName(line[1:])
+	if name == "" {
+		return nil
+	}
+
+	if collected, ok := pc.Get(collectMentionsKey).(*[]string); ok {
+		*collected = append(*collected, name)
+		return nil
+	}
+	known, _ := pc.Get(knownMentionsKey).(map[string]bool)
+	if !known[name] {
+		return nil
+	}
+
+	length := 1 + len(name)
+	link := ast.NewLink()
+	link.Destination = []byte("/u/" + url.PathEscape(name))
+	link.SetAttributeString("class", []byte("mention"))
+	link.AppendChild(link, ast.NewTextSegment(text.NewSegment(segment.Start, segment.Start+length)))
+	block.Advance(length)
+	return link
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
+
+// Satırın başındaki kullanıcı adını döndürür; cümle sonundaki nokta ve tire ada dahil edilmez.
+func mentionName(line []byte) string {
+	end := 0
+	for end < len(line) {
+		r, size := utf8.DecodeRune(line[end:])
+		if !isNameRune(r) {
+			break
+		}
+		end += size
+	}
+	return strings.TrimRight(string(line[:end]), ".-")
+}
+
+// Mentions, içerikte bahsedilen farklı kullanıcı adlarını geçtikleri sırayla döndürür
+// (en fazla MaxMentions). Kod blokları ve satır içi kodlar atlanır.
+func Mentions(source string) []string {
+	var collected []string
+	ctx := parser.NewContext()
+	ctx.Set(collectMentionsKey, &collected)
+	md.Parser().Parse(text.NewReader([]byte(source)), parser.WithContext(ctx))
+
+	seen := make(map[string]bool, len(collected))
+	names := make([]string, 0, len(collected))
+	for _, name := range collected {
+		if seen[name] || len(names) == MaxMentions {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,120 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"form-project/datahandlers"
+	"form-project/utils"
+
+	"github.com/google/uuid"
+	xdraw "golang.org/x/image/draw"
+)
+
+// AvatarSize, profil fotoğraflarının kare kenar uzunluğudur (piksel).
+const AvatarSize = 256
+
+// Profil fotoğrafı olmayan kullanıcılar için gösterilen görsel
+const defaultAvatar = "/static/png/pp.png"
+
+// AvatarFromForm, formdaki profil fotoğrafını işleyip kaydeder. Alan boşsa (nil, nil) döner.
+func AvatarFromForm(r *http.Request, field string, userID int) (*Attachment, error) {
+	file, header, err := r.FormFile(field)
+	if err == http.ErrMissingFile {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, utils.BadRequest("Error getting file", err)
+	}
+	defer file.Close()
+
+	if err := checkQuota(userID, header.Size); err != nil {
+		return nil, err
+	}
+	return SaveAvatar(r.Context(), file, header.Filename, userID)
+}
+
+// SaveAvatar, görseli diğer yüklemeler gibi doğrulayıp yeniden kodlar, ortasından kare
+// olarak kırpar ve AvatarSize boyutuna küçültür. Animasyonlu GIF'lerin ilk karesi PNG
+// olarak kaydedilir. Profil fotoğrafları için varyant üretilmez.
+func SaveAvatar(ctx context.Context, r io.Reader, originalName string, userID int) (*Attachment, error) {
+	data, contentType, err := readUpload(r)
+	if err != nil {
+		return nil, err
+	}
+	if !fileTypes[contentType].image {
+		return nil, utils.BadRequest("Unsupported image format. Please upload a JPEG, PNG, or GIF image",
+			fmt.Errorf("%w: %s", ErrUnsupportedType, contentType))
+	}
+	if int64(len(data)) > maxFileSize["image"] {
+		return nil, utils.BadRequest(fmt.Sprintf("File size exceeds limit (%s)", datahandlers.FormatSize(maxFileSize["image"])), ErrTooLarge)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, utils.BadRequest("Invalid image", fmt.Errorf("%w: %v", ErrInvalidImage, err))
+	}
+	if err := checkDimensions(config.Width, config.Height, 1); err != nil {
+		return nil, err
+	}
+
+	// Yeniden kodlama yönlendirmeyi uygular ve meta verileri atar; çözülmüş görsel kırpılır
+	_, img, err := reencode(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+	avatar := cropSquare(img, AvatarSize)
+
+	var buf bytes.Buffer
+	ext := ".png"
+	if contentType == "image/jpeg" {
+		ext = ".jpg"
+		err = jpeg.Encode(&buf, avatar, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		contentType = "image/png"
+		err = png.Encode(&buf, avatar)
+	}
+	if err != nil {
+		return nil, utils.Internal(err)
+	}
+
+	attachment := &Attachment{
+		Filename:     uuid.New().String() + ext,
+		OriginalName: filepath.Base(originalName),
+		ContentType:  contentType,
+		Size:         int64(buf.Len()),
+		Width:        AvatarSize,
+		Height:       AvatarSize,
+	}
+	if err := store(ctx, attachment, buf.Bytes(), nil, "avatar", userID); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// Görselin ortasından en büyük kareyi kırpar ve size x size boyutuna getirir.
+// size'dan küçük görseller büyütülmez.
+func cropSquare(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2))
+	size = min(size, side)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, xdraw.Src, nil)
+	return dst
+}
+
+// Profil fotoğrafının adresi; fotoğraf yoksa varsayılan görsel.
+func avatarURL(key string) string {
+	if key == "" {
+		return defaultAvatar
+	}
+	return Store.URL(key)
+}
@@ -0,0 +1,155 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"form-project/datahandlers"
+	"form-project/storage"
+)
+
+// GCReport, çöp toplama çalışmasının özetidir.
+type GCReport struct {
+	Marked    int64    // Bu çalışmada ilk kez sahipsiz görülen ekler
+	Deleted   []string // Bekleme süresi dolduğu için silinen (dry-run'da silinecek) ekler
+	Pending   []string // Sahipsiz ama bekleme süresi henüz dolmamış ekler
+	Untracked []string // Hiçbir kaydın bilmediği, silinen (dry-run'da silinecek) dosyalar
+	Failed    []string // Silinemeyen dosyalar ve nedenleri
+}
+
+// CollectGarbage, hiçbir gönderi, yorum veya profil tarafından kullanılmayan dosyaları siler.
+// Bir ek ilk kez sahipsiz görüldüğünde işaretlenir ve ancak grace süresi geçtikten sonra
+// silinir; kaydı hiç olmayan dosyalar için depodaki değiştirilme zamanı esas alınır.
+// PrivatePrefix dışındaki alt dizinlerdeki dosyalara dokunulmaz. dryRun true ise hiçbir şey
+// değiştirilmez, yalnızca rapor üretilir.
+func CollectGarbage(ctx context.Context, grace time.Duration, dryRun bool) (GCReport, error) {
+	var report GCReport
+
+	if !dryRun {
+		marked, err := datahandlers.MarkOrphanedAttachments()
+		if err != nil {
+			return report, fmt.Errorf("mark orphaned attachments: %w", err)
+		}
+		report.Marked = marked
+	}
+
+	expired, err := datahandlers.OrphanedAttachments(grace, false)
+	if err != nil {
+		return report, err
+	}
+	expiredIDs := make(map[int64]bool, len(expired))
+	for _, a := range expired {
+		expiredIDs[a.ID] = true
+		if dryRun {
+			report.Deleted = append(report.Deleted, a.StorageKey)
+			continue
+		}
+		if err := deleteWithVariants(ctx, a.StorageKey); err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", a.StorageKey, err))
+			continue
+		}
+		if err := datahandlers.DeleteAttachment(a.ID); err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", a.StorageKey, err))
+			continue
+		}
+		report.Deleted = append(report.Deleted, a.StorageKey)
+	}
+
+	orphaned, err := datahandlers.OrphanedAttachments(0, true)
+	if err != nil {
+		return report, err
+	}
+	for _, a := range orphaned {
+		if !expiredIDs[a.ID] {
+			report.Pending = append(report.Pending, a.StorageKey)
+		}
+	}
+
+	if err := collectUntracked(ctx, grace, dryRun, &report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// Kaydı olmayan (ör. attachments tablosundan önce yüklenmiş veya yarıda kalmış) dosyaları bulur.
+func collectUntracked(ctx context.Context, grace time.Duration, dryRun bool, report *GCReport) error {
+	known, err := datahandlers.KnownStorageKeys()
+	if err != nil {
+		return err
+	}
+	for key := range known {
+		for _, v := range Variants {
+			known[VariantName(key, v)] = true
+		}
+	}
+
+	keys, err := Store.List(ctx, "")
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-grace)
+	for _, key := range keys {
+		if strings.Contains(strings.TrimPrefix(key, storage.PrivatePrefix), "/") {
+			continue
+		}
+		if known[key] {
+			continue
+		}
+		info, err := Store.Stat(ctx, key)
+		if err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		if info.ModTime.After(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := Store.Delete(ctx, key); err != nil {
+				report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", key, err))
+				continue
+			}
+			existence.Delete(key)
+		}
+		report.Untracked = append(report.Untracked, key)
+	}
+	return nil
+}
+
+// Dosyayı ve tüm varyantlarını siler.
+func deleteWithVariants(ctx context.Context, key string) error {
+	for _, v := range Variants {
+		name := VariantName(key, v)
+		if err := Store.Delete(ctx, name); err != nil {
+			return err
+		}
+		existence.Delete(name)
+	}
+	return Store.Delete(ctx, key)
+}
+
+// StartGC, çöp toplayıcıyı arka planda her interval'de bir çalıştırır. interval sıfırsa çalışmaz.
+func StartGC(interval, grace time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			report, err := CollectGarbage(ctx, grace, false)
+			cancel()
+			if err != nil {
+				log.Printf("upload gc: %v", err)
+				continue
+			}
+			if report.Marked > 0 || len(report.Deleted) > 0 || len(report.Untracked) > 0 || len(report.Failed) > 0 {
+				log.Printf("upload gc: marked %d, deleted %d attachments and %d untracked files, %d failed",
+					report.Marked, len(report.Deleted), len(report.Untracked), len(report.Failed))
+			}
+		}
+	}()
+}
@@ -0,0 +1,63 @@
+package media
+
+import "errors"
+
+var errGIFStructure = errors.New("malformed GIF block structure")
+
+// GIF'in blok yapısını kareleri çözmeden dolaşarak kare sayısını döndürür. gif.DecodeAll tüm
+// kareleri belleğe açtığından kare sayısı sıkıştırma bombalarına karşı çözümlemeden önce denetlenir.
+func gifFrames(data []byte) (int, error) {
+	// Başlık (6) ve mantıksal ekran tanımı (7)
+	if len(data) < 13 {
+		return 0, errGIFStructure
+	}
+	i := 13
+	if packed := data[10]; packed&0x80 != 0 {
+		i += 3 << ((packed & 0x07) + 1) // Genel renk tablosu
+	}
+
+	frames := 0
+	for i < len(data) {
+		switch data[i] {
+		case 0x21: // Uzantı: etiket ve alt bloklar
+			next, ok := skipSubBlocks(data, i+2)
+			if !ok {
+				return 0, errGIFStructure
+			}
+			i = next
+		case 0x2C: // Görüntü tanımı (9), yerel renk tablosu, LZW kod boyu ve alt bloklar
+			if i+11 > len(data) {
+				return 0, errGIFStructure
+			}
+			j := i + 10
+			if packed := data[i+9]; packed&0x80 != 0 {
+				j += 3 << ((packed & 0x07) + 1)
+			}
+			next, ok := skipSubBlocks(data, j+1)
+			if !ok {
+				return 0, errGIFStructure
+			}
+			frames++
+			i = next
+		case 0x3B: // Bitiş
+			return frames, nil
+		default:
+			return 0, errGIFStructure
+		}
+	}
+	// gif paketi bitiş baytı olmayan dosyaları da kabul eder
+	return frames, nil
+}
+
+// i konumundan başlayan alt blok dizisini (boyut baytı ve veri, 0 ile biter) atlar.
+func skipSubBlocks(data []byte, i int) (int, bool) {
+	for i < len(data) {
+		size := int(data[i])
+		i++
+		if size == 0 {
+			return i, true
+		}
+		i += size
+	}
+	return 0, false
+}
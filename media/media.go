@@ -0,0 +1,427 @@
+package media // Yüklenen görselleri ve belgeleri doğrulayan, görselleri yeniden kodlayan ve kaydeden yükleme servisi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"form-project/config"
+	"form-project/datahandlers"
+	"form-project/metrics"
+	"form-project/reputation"
+	"form-project/storage"
+	"form-project/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MaxDimension     = 8000       // Tek kenar için en fazla piksel
+	MaxPixels        = 40_000_000 // Bir görselin (GIF için tüm karelerin) toplam piksel sınırı
+	MaxCaptionLength = 200
+	jpegQuality      = 85
+	FileLinkTTL      = time.Hour // Belge eklerinin imzalı indirme adreslerinin geçerlilik süresi
+)
+
+// Dosyaların kaydedildiği depo; main yapılandırmadaki arka ucu SetStore ile ayarlar.
+var Store storage.BlobStore = storage.NewLocal("./uploads")
+
+func SetStore(store storage.BlobStore) {
+	Store = store
+}
+
+// Doğrulama hataları; işleyiciler utils.WriteError ile 400 olarak döndürür.
+var (
+	ErrTooLarge        = errors.New("file exceeds upload size limit")
+	ErrTooMany         = errors.New("too many files")
+	ErrUnsupportedType = errors.New("unsupported file type")
+	ErrDimensions      = errors.New("image dimensions exceed limit")
+	ErrInvalidImage    = errors.New("invalid image data")
+	ErrInvalidDocument = errors.New("invalid document data")
+	ErrQuotaExceeded   = errors.New("upload quota exceeded")
+)
+
+// fileType, kabul edilen bir içerik türünün kayıt uzantısı ve boyut sınırının türüdür.
+type fileType struct {
+	ext   string
+	kind  string // maxFileSize anahtarı: image, pdf veya text
+	image bool
+}
+
+// İçerik türüne göre kabul edilen dosyalar; yalnızca bu türler kaydedilir.
+var fileTypes = map[string]fileType{
+	"image/jpeg":                {".jpg", "image", true},
+	"image/png":                 {".png", "image", true},
+	"image/gif":                 {".gif", "image", true},
+	"application/pdf":           {".pdf", "pdf", false},
+	"text/plain; charset=utf-8": {".txt", "text", false},
+}
+
+// Türe göre tek dosya boyut sınırları (bayt); main yapılandırmadan SetLimits ile ayarlar.
+var maxFileSize = map[string]int64{"image": 20 << 20, "pdf": 10 << 20, "text": 1 << 20}
+
+// SetLimits, yapılandırmadaki dosya boyutu sınırlarını ve rol kotalarını uygular.
+// Sunucu istek almaya başlamadan önce çağrılmalıdır.
+func SetLimits(cfg config.Uploads) {
+	for kind, mb := range cfg.MaxFileSizeMB {
+		maxFileSize[kind] = mb << 20
+	}
+	for role, mb := range cfg.QuotaMB {
+		roleQuotas[role] = mb << 20
+	}
+}
+
+// MaxFileSize, herhangi bir türdeki tek bir dosya için en büyük sınırdır.
+func MaxFileSize() int64 {
+	var max int64
+	for _, size := range maxFileSize {
+		if size > max {
+			max = size
+		}
+	}
+	return max
+}
+
+// MaxRequestSize, çok dosyalı bir formun toplam boyut sınırıdır (en az 60 MB).
+func MaxRequestSize() int64 {
+	if size := 3 * MaxFileSize(); size > 60<<20 {
+		return size
+	}
+	return 60 << 20
+}
+
+// Attachment, kaydedilmiş bir yüklemenin bilgileridir.
+type Attachment struct {
+	ID           int64  // attachments tablosundaki kayıt
+	Filename     string // Depodaki anahtar dosya adı (posts.image_path vb. sütunlarda saklanan değer)
+	OriginalName string // Kullanıcının yüklediği dosyanın adı
+	ContentType  string // Koklanan (sniffed) gerçek içerik türü
+	Size         int64  // Kaydedilen dosyanın bayt cinsinden boyutu
+	Width        int    // Yalnızca görseller için
+	Height       int
+	Caption      string // FromFormFiles ile gelen açıklama
+}
+
+func (a *Attachment) IsImage() bool {
+	return fileTypes[a.ContentType].image
+}
+
+// FromForm, formdaki tek görsel alanını işleyip kaydeder. Alan boşsa (nil, nil) döner.
+// source metriklerde yüklemenin nereden geldiğini belirtir (post, comment, file); userID
+// yükleyen kullanıcıdır; oturumsuz (0) yüklemeler reddedilir. Dönen ek, çağıran tarafından bir gönderiye veya
+// yoruma bağlanmazsa çöp toplayıcı tarafından silinir.
+func FromForm(r *http.Request, field, source string, userID int) (*Attachment, error) {
+	file, header, err := r.FormFile(field)
+	if err == http.ErrMissingFile {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, utils.BadRequest("Error getting file", err)
+	}
+	defer file.Close()
+
+	if header.Size > maxFileSize["image"] {
+		return nil, utils.BadRequest(fmt.Sprintf("File size exceeds limit (%s)", datahandlers.FormatSize(maxFileSize["image"])), ErrTooLarge)
+	}
+	if err := checkQuota(userID, header.Size); err != nil {
+		return nil, err
+	}
+	if err := checkImagePrivilege(userID); err != nil {
+		return nil, err
+	}
+	return SaveImage(r.Context(), file, header.Filename, source, userID)
+}
+
+// FromFormFiles, formdaki çoklu dosya alanını sırasıyla işleyip kaydeder. captionField
+// alanının aynı sıradaki değeri dosyanın açıklaması olur. Görseller ile PDF ve metin
+// dosyaları kabul edilir; en fazla max dosya yüklenebilir. Form ParseMultipartForm ile
+// ayrıştırılmış olmalıdır.
+func FromFormFiles(r *http.Request, field, captionField, source string, userID, max int) ([]*Attachment, error) {
+	if r.MultipartForm == nil {
+		return nil, nil
+	}
+	headers := r.MultipartForm.File[field]
+	captions := r.MultipartForm.Value[captionField]
+	if len(headers) > max {
+		return nil, utils.BadRequest(fmt.Sprintf("You can attach at most %d files", max), ErrTooMany)
+	}
+	// Kota, dosyalar işlenmeden önce toplam boyutla bir kez kontrol edilir; böylece
+	// kotayı aşan bir form yarısı kaydedilmiş halde kalmaz
+	var total int64
+	for _, header := range headers {
+		total += header.Size
+	}
+	if err := checkQuota(userID, total); err != nil {
+		return nil, err
+	}
+	for _, header := range headers {
+		image, err := isImage(header)
+		if err != nil {
+			return nil, err
+		}
+		if image {
+			if err := checkImagePrivilege(userID); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	var attachments []*Attachment
+	for i, header := range headers {
+		caption := ""
+		if i < len(captions) {
+			caption = strings.TrimSpace(captions[i])
+		}
+		if utf8.RuneCountInString(caption) > MaxCaptionLength {
+			return nil, utils.BadRequest(fmt.Sprintf("Captions can be at most %d characters", MaxCaptionLength), nil)
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			return nil, utils.BadRequest("Error getting file", err)
+		}
+		attachment, err := SaveFile(r.Context(), file, header.Filename, source, userID)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		attachment.Caption = caption
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// SaveImage, verinin gerçek içerik türünü koklar, görseli çözer ve yeniden kodlayarak
+// kaydeder. Yeniden kodlama EXIF/GPS gibi tüm meta verileri atar; JPEG yönlendirmesi
+// atılmadan önce piksellere uygulanır.
+func SaveImage(ctx context.Context, r io.Reader, originalName, source string, userID int) (*Attachment, error) {
+	data, contentType, err := readUpload(r)
+	if err != nil {
+		return nil, err
+	}
+	if !fileTypes[contentType].image {
+		return nil, utils.BadRequest("Unsupported image format. Please upload a JPEG, PNG, or GIF image",
+			fmt.Errorf("%w: %s", ErrUnsupportedType, contentType))
+	}
+	return saveImage(ctx, data, contentType, originalName, source, userID)
+}
+
+// SaveFile, görselleri SaveImage gibi işler; PDF ve metin dosyalarını türüne özgü boyut
+// sınırı ve içerik doğrulamasından sonra olduğu gibi kaydeder.
+func SaveFile(ctx context.Context, r io.Reader, originalName, source string, userID int) (*Attachment, error) {
+	data, contentType, err := readUpload(r)
+	if err != nil {
+		return nil, err
+	}
+	ft, ok := fileTypes[contentType]
+	if !ok {
+		return nil, utils.BadRequest("Unsupported file type. Please upload an image (JPEG, PNG, GIF), a PDF or a text file",
+			fmt.Errorf("%w: %s", ErrUnsupportedType, contentType))
+	}
+	if max := maxFileSize[ft.kind]; int64(len(data)) > max {
+		return nil, utils.BadRequest(fmt.Sprintf("%s exceeds the size limit for this file type (%s)", filepath.Base(originalName), datahandlers.FormatSize(max)),
+			ErrTooLarge)
+	}
+	if ft.image {
+		return saveImage(ctx, data, contentType, originalName, source, userID)
+	}
+
+	switch contentType {
+	case "application/pdf":
+		if !bytes.HasPrefix(data, []byte("%PDF-")) {
+			return nil, utils.BadRequest("Invalid PDF file", ErrInvalidDocument)
+		}
+	default: // text/plain
+		if !utf8.Valid(data) {
+			return nil, utils.BadRequest("Text files must be UTF-8 encoded", ErrInvalidDocument)
+		}
+	}
+
+	// Belgeler yalnızca gönderi sayfasında üretilen, süresi sınırlı imzalı adreslerle indirilir
+	attachment := &Attachment{
+		Filename:     storage.PrivatePrefix + uuid.New().String() + ft.ext,
+		OriginalName: filepath.Base(originalName),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+	}
+	if err := store(ctx, attachment, data, nil, source, userID); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// Görsel eklemek puanla açılan bir yetkidir; dosyalar kaydedilmeden önce kontrol edilir.
+func checkImagePrivilege(userID int) error {
+	if userID == 0 {
+		return errAnonymousUpload
+	}
+	return reputation.Check(userID, reputation.PostImages)
+}
+
+// Dosyanın ilk baytlarını koklayarak görsel olup olmadığını döndürür.
+func isImage(header *multipart.FileHeader) (bool, error) {
+	file, err := header.Open()
+	if err != nil {
+		return false, utils.BadRequest("Error getting file", err)
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, utils.BadRequest("Error reading file", err)
+	}
+	return fileTypes[http.DetectContentType(head[:n])].image, nil
+}
+
+// Yüklemeyi en büyük sınıra kadar okur ve gerçek içerik türünü koklar.
+func readUpload(r io.Reader) ([]byte, string, error) {
+	max := MaxFileSize()
+	data, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, "", utils.BadRequest("Error reading file", err)
+	}
+	if int64(len(data)) > max {
+		return nil, "", utils.BadRequest(fmt.Sprintf("File size exceeds limit (%s)", datahandlers.FormatSize(max)), ErrTooLarge)
+	}
+	return data, http.DetectContentType(data), nil
+}
+
+func saveImage(ctx context.Context, data []byte, contentType, originalName, source string, userID int) (*Attachment, error) {
+	// Tam çözümlemeden önce boyutlar kontrol edilir (sıkıştırma bombalarına karşı)
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, utils.BadRequest("Invalid image", fmt.Errorf("%w: %v", ErrInvalidImage, err))
+	}
+	if "image/"+format != contentType {
+		return nil, utils.BadRequest("Invalid image", fmt.Errorf("%w: content is %s but decodes as %s", ErrInvalidImage, contentType, format))
+	}
+	if err := checkDimensions(config.Width, config.Height, 1); err != nil {
+		return nil, err
+	}
+
+	encoded, img, err := reencode(data, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &Attachment{
+		Filename:     uuid.New().String() + fileTypes[contentType].ext,
+		OriginalName: filepath.Base(originalName),
+		ContentType:  contentType,
+		Size:         int64(len(encoded)),
+		Width:        img.Bounds().Dx(),
+		Height:       img.Bounds().Dy(),
+	}
+	if err := store(ctx, attachment, encoded, img, source, userID); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// Eki kaydeder, dosyayı (ve görselse varyantlarını) depoya yazar.
+func store(ctx context.Context, attachment *Attachment, data []byte, img image.Image, source string, userID int) error {
+	if err := checkQuota(userID, attachment.Size); err != nil {
+		return err
+	}
+	// Kayıt dosyadan önce oluşturulur: yazma yarıda kalırsa da çöp toplayıcı dosyayı bilir
+	// ve bekleme süresi dolmadan silmez.
+	id, err := datahandlers.CreateAttachment(datahandlers.Attachment{
+		StorageKey:   attachment.Filename,
+		OriginalName: attachment.OriginalName,
+		ContentType:  attachment.ContentType,
+		Size:         attachment.Size,
+		Width:        attachment.Width,
+		Height:       attachment.Height,
+		UserID:       userID,
+	})
+	if err != nil {
+		return utils.Internal(err)
+	}
+	attachment.ID = id
+
+	if err := Store.Put(ctx, attachment.Filename, data, attachment.ContentType); err != nil {
+		return utils.Internal(err)
+	}
+	if img != nil {
+		if err := writeVariants(ctx, img, attachment.Filename); err != nil {
+			return utils.Internal(err)
+		}
+	}
+	metrics.Uploaded(source, attachment.Size)
+	return nil
+}
+
+func checkDimensions(width, height, frames int) error {
+	if width <= 0 || height <= 0 {
+		return utils.BadRequest("Invalid image", ErrInvalidImage)
+	}
+	if width > MaxDimension || height > MaxDimension || width*height*frames > MaxPixels {
+		return utils.BadRequest(fmt.Sprintf("Image is too large (max %dx%d pixels)", MaxDimension, MaxDimension),
+			fmt.Errorf("%w: %dx%d, %d frames", ErrDimensions, width, height, frames))
+	}
+	return nil
+}
+
+// Görseli çözüp aynı biçimde, meta veri olmadan yeniden kodlar. Varyantlar için
+// çözülmüş görseli de döndürür (animasyonlu GIF'lerde ilk kare).
+func reencode(data []byte, contentType string) ([]byte, image.Image, error) {
+	var buf bytes.Buffer
+
+	switch contentType {
+	case "image/gif":
+		// Animasyonlu GIF'lerde tüm kareler korunur; kareler çözülmeden önce sayılır
+		config, err := gif.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, utils.BadRequest("Invalid image", fmt.Errorf("%w: %v", ErrInvalidImage, err))
+		}
+		frames, err := gifFrames(data)
+		if err != nil {
+			return nil, nil, utils.BadRequest("Invalid image", fmt.Errorf("%w: %v", ErrInvalidImage, err))
+		}
+		if err := checkDimensions(config.Width, config.Height, frames); err != nil {
+			return nil, nil, err
+		}
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, utils.BadRequest("Invalid image", fmt.Errorf("%w: %v", ErrInvalidImage, err))
+		}
+		if err := gif.EncodeAll(&buf, g); err != nil {
+			return nil, nil, utils.Internal(err)
+		}
+		return buf.Bytes(), g.Image[0], nil
+
+	case "image/png":
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, utils.BadRequest("Invalid image", fmt.Errorf("%w: %v", ErrInvalidImage, err))
+		}
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, nil, utils.Internal(err)
+		}
+		return buf.Bytes(), img, nil
+
+	default: // image/jpeg
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, utils.BadRequest("Invalid image", fmt.Errorf("%w: %v", ErrInvalidImage, err))
+		}
+		img = applyOrientation(img, jpegOrientation(data))
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, nil, utils.Internal(err)
+		}
+		return buf.Bytes(), img, nil
+	}
+}
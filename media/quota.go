@@ -0,0 +1,166 @@
+package media
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"form-project/datahandlers"
+	"form-project/utils"
+)
+
+// Role göre kullanıcı başına toplam yükleme kotası (bayt, 0 sınırsız); main yapılandırmadan
+// SetLimits ile ayarlar. Admin panelinde tanımlanan rol kotaları bunları ezer.
+var roleQuotas = map[string]int64{"user": 100 << 20, "moderator": 500 << 20, "admin": 0}
+
+// Quota, bir kullanıcının yükleme kotası ve kullanımıdır.
+type Quota struct {
+	Used   int64
+	Limit  int64  // 0 sınırsız
+	Source string // Kotanın nereden geldiği: "user", "role" veya "config"
+}
+
+func (q Quota) Unlimited() bool {
+	return q.Limit == 0
+}
+
+// Remaining, kalan kotadır; sınırsız kotalarda -1 döner.
+func (q Quota) Remaining() int64 {
+	if q.Unlimited() {
+		return -1
+	}
+	if q.Used >= q.Limit {
+		return 0
+	}
+	return q.Limit - q.Used
+}
+
+// Percent, kullanılan kotanın yüzdesidir (0-100).
+func (q Quota) Percent() int {
+	if q.Unlimited() {
+		return 0
+	}
+	if q.Used >= q.Limit {
+		return 100
+	}
+	return int(q.Used * 100 / q.Limit)
+}
+
+func (q Quota) UsedLabel() string {
+	return datahandlers.FormatSize(q.Used)
+}
+
+func (q Quota) LimitLabel() string {
+	if q.Unlimited() {
+		return "unlimited"
+	}
+	return datahandlers.FormatSize(q.Limit)
+}
+
+// QuotaFor, kullanıcının geçerli kotasını döndürür: kullanıcıya özel kota, yoksa adminin
+// rol için tanımladığı kota, o da yoksa yapılandırmadaki rol kotası.
+func QuotaFor(userID int) (Quota, error) {
+	role, userQuota, roleQuota, err := datahandlers.UploadQuota(userID)
+	if err != nil {
+		return Quota{}, fmt.Errorf("error reading upload quota of user %d: %v", userID, err)
+	}
+	used, err := datahandlers.UploadUsage(userID)
+	if err != nil {
+		return Quota{}, err
+	}
+
+	quota := Quota{Used: used}
+	switch {
+	case userQuota.Valid:
+		quota.Limit, quota.Source = userQuota.Int64, "user"
+	case roleQuota.Valid:
+		quota.Limit, quota.Source = roleQuota.Int64, "role"
+	default:
+		quota.Limit, quota.Source = configuredQuota(role), "config"
+	}
+	return quota, nil
+}
+
+// Yapılandırmada rol için kota yoksa "user" rolününki kullanılır.
+func configuredQuota(role string) int64 {
+	if quota, ok := roleQuotas[role]; ok {
+		return quota
+	}
+	return roleQuotas["user"]
+}
+
+// RoleQuota, admin panelinde gösterilen bir rolün kota bilgisidir.
+type RoleQuota struct {
+	Role       string
+	Configured int64         // config.json'daki (veya varsayılan) değer
+	Override   sql.NullInt64 // Adminin tanımladığı değer
+}
+
+// Effective, rol için geçerli kotadır.
+func (rq RoleQuota) Effective() Quota {
+	if rq.Override.Valid {
+		return Quota{Limit: rq.Override.Int64, Source: "role"}
+	}
+	return Quota{Limit: rq.Configured, Source: "config"}
+}
+
+func (rq RoleQuota) ConfiguredLabel() string {
+	return Quota{Limit: rq.Configured}.LimitLabel()
+}
+
+// OverrideMB, admin formunda gösterilen MB cinsinden değerdir; tanımlı değilse boş döner.
+func (rq RoleQuota) OverrideMB() string {
+	if !rq.Override.Valid {
+		return ""
+	}
+	return strconv.FormatInt(rq.Override.Int64>>20, 10)
+}
+
+// RoleQuotas, yapılandırmada veya veritabanında kotası olan tüm rolleri ada göre sıralı döndürür.
+func RoleQuotas() ([]RoleQuota, error) {
+	overrides, err := datahandlers.RoleQuotas()
+	if err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[string]*RoleQuota)
+	for role, quota := range roleQuotas {
+		byRole[role] = &RoleQuota{Role: role, Configured: quota}
+	}
+	for role, quota := range overrides {
+		if byRole[role] == nil {
+			byRole[role] = &RoleQuota{Role: role, Configured: configuredQuota(role)}
+		}
+		byRole[role].Override = sql.NullInt64{Int64: quota, Valid: true}
+	}
+
+	quotas := make([]RoleQuota, 0, len(byRole))
+	for _, rq := range byRole {
+		quotas = append(quotas, *rq)
+	}
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].Role < quotas[j].Role })
+	return quotas, nil
+}
+
+// Yükleyeni bilinmeyen dosyalar kotaya ve yetkilere göre denetlenemez
+var errAnonymousUpload = utils.Unauthorized("You need to log in to upload files")
+
+// Kullanıcının size bayt daha yükleyip yükleyemeyeceğini kontrol eder. Oturumsuz
+// yüklemeler (userID 0) reddedilir.
+func checkQuota(userID int, size int64) error {
+	if userID == 0 {
+		return errAnonymousUpload
+	}
+	quota, err := QuotaFor(userID)
+	if err != nil {
+		return utils.Internal(err)
+	}
+	if quota.Unlimited() || quota.Used+size <= quota.Limit {
+		return nil
+	}
+	return utils.NewError(http.StatusRequestEntityTooLarge,
+		fmt.Sprintf("Upload quota exceeded: you have used %s of %s", quota.UsedLabel(), quota.LimitLabel()),
+		fmt.Errorf("%w: user %d, %d + %d > %d bytes", ErrQuotaExceeded, userID, quota.Used, size, quota.Limit))
+}
@@ -0,0 +1,286 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"form-project/datahandlers"
+	"form-project/storage"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Variant, yükleme sırasında üretilen küçültülmüş bir görsel boyutudur.
+type Variant struct {
+	Name    string // Dosya adına eklenen sonek (uuid_<Name>.jpg)
+	MaxSize int    // En uzun kenarın piksel sınırı
+}
+
+// Şablonlardaki srcset değeri bu sırayla (küçükten büyüğe) oluşturulur.
+var Variants = []Variant{
+	{Name: "thumb", MaxSize: 320},
+	{Name: "medium", MaxSize: 1024},
+}
+
+// VariantName, orijinal dosya adından varyantın dosya adını üretir. JPEG ve PNG
+// biçimlerini korur; GIF varyantları ilk kareden PNG olarak üretilir.
+func VariantName(filename string, v Variant) string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	if ext == ".gif" {
+		ext = ".png"
+	}
+	return base + "_" + v.Name + ext
+}
+
+// Dosya adının bir varyanta ait olup olmadığını döndürür (geriye dönük üretimde atlanır).
+func isVariant(filename string) bool {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	for _, v := range Variants {
+		if strings.HasSuffix(base, "_"+v.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Görselin tüm varyantlarını üretip kaydeder. Görsel varyant sınırından küçükse büyütülmez,
+// aynı boyutta yeniden kodlanır; böylece şablonlar her zaman varyant dosyasına güvenebilir.
+func writeVariants(ctx context.Context, img image.Image, filename string) error {
+	for _, v := range Variants {
+		if err := writeVariant(ctx, img, filename, v); err != nil {
+			return fmt.Errorf("variant %s of %s: %w", v.Name, filename, err)
+		}
+	}
+	return nil
+}
+
+func writeVariant(ctx context.Context, img image.Image, filename string, v Variant) error {
+	name := VariantName(filename, v)
+	scaled := resize(img, v.MaxSize)
+
+	var buf bytes.Buffer
+	var err error
+	contentType := "image/png"
+	if filepath.Ext(name) == ".jpg" {
+		contentType = "image/jpeg"
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, scaled)
+	}
+	if err != nil {
+		return err
+	}
+	if err := Store.Put(ctx, name, buf.Bytes(), contentType); err != nil {
+		return err
+	}
+	existence.Store(name, existsEntry{exists: true})
+	return nil
+}
+
+// En uzun kenarı maxSize olacak şekilde oranı koruyarak küçültür; küçük görselleri olduğu gibi bırakır.
+func resize(img image.Image, maxSize int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSize && h <= maxSize {
+		return img
+	}
+
+	if w >= h {
+		h = max(1, h*maxSize/w)
+		w = maxSize
+	} else {
+		w = max(1, w*maxSize/h)
+		h = maxSize
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, xdraw.Src, nil)
+	return dst
+}
+
+// BackfillReport, Backfill çalışmasının özetidir.
+type BackfillReport struct {
+	Scanned   int      // İncelenen orijinal dosya sayısı
+	Generated int      // Üretilen (dry-run'da üretilecek) varyant sayısı
+	Failed    []string // İşlenemeyen dosyalar ve nedenleri
+}
+
+// Backfill, depodaki eski yüklemeler için eksik varyantları üretir.
+// dryRun true ise hiçbir dosya yazılmaz, yalnızca üretilecek varyantlar sayılır.
+func Backfill(ctx context.Context, dryRun bool) (BackfillReport, error) {
+	var report BackfillReport
+
+	keys, err := Store.List(ctx, "")
+	if err != nil {
+		return report, err
+	}
+	for _, name := range keys {
+		// Alt dizinlerdeki dosyalar (ör. private/ altındaki belgeler) görsel değildir
+		if strings.Contains(name, "/") || isVariant(name) || !hasImageExtension(name) {
+			continue
+		}
+		report.Scanned++
+
+		var missing []Variant
+		for _, v := range Variants {
+			_, err := Store.Stat(ctx, VariantName(name, v))
+			if errors.Is(err, storage.ErrNotFound) {
+				missing = append(missing, v)
+			} else if err != nil {
+				report.Failed = append(report.Failed, fmt.Sprintf("%s (%s): %v", name, v.Name, err))
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		if dryRun {
+			report.Generated += len(missing)
+			continue
+		}
+
+		img, err := decodeFile(ctx, name)
+		if err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		for _, v := range missing {
+			if err := writeVariant(ctx, img, name, v); err != nil {
+				report.Failed = append(report.Failed, fmt.Sprintf("%s (%s): %v", name, v.Name, err))
+				continue
+			}
+			report.Generated++
+		}
+	}
+	return report, nil
+}
+
+func hasImageExtension(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, ft := range fileTypes {
+		if ft.image && ext == ft.ext {
+			return true
+		}
+	}
+	return ext == ".jpeg"
+}
+
+// Kayıtlı bir dosyayı kenar sınırını kontrol ederek çözer (GIF için ilk kare). Toplam piksel
+// sınırı uygulanmaz; bu sınırdan önce kabul edilmiş eski yüklemeler de işlenebilsin.
+func decodeFile(ctx context.Context, name string) (image.Image, error) {
+	obj, err := Store.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(obj.Body, MaxFileSize()+1))
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension {
+		return nil, fmt.Errorf("%w: %dx%d", ErrDimensions, config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// TemplateFuncs, yüklenen görseller için şablon yardımcılarını döndürür (render.AddFuncs ile eklenir).
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"imageURL":   imageURL,
+		"fileURL":    fileURL,
+		"thumbURL":   thumbURL,
+		"srcset":     srcset,
+		"avatarURL":  avatarURL,
+		"avatarSize": func() int { return AvatarSize },
+
+		// Türe göre tek dosya sınırı (bayt) ve okunabilir boyut, dosya seçicinin istemci kontrolleri için
+		"uploadLimit": func(kind string) int64 { return maxFileSize[kind] },
+		"fileSize":    datahandlers.FormatSize,
+	}
+}
+
+func imageURL(filename string) string {
+	return Store.URL(filename)
+}
+
+// Özel (belge) eklerine imzalı, diğerlerine kalıcı adres verir. İmzalı adres üretilemezse boş döner.
+func fileURL(key string) string {
+	if !strings.HasPrefix(key, storage.PrivatePrefix) {
+		return imageURL(key)
+	}
+	u, err := Store.SignedURL(key, FileLinkTTL)
+	if err != nil {
+		log.Printf("signed url for %s: %v", key, err)
+		return ""
+	}
+	return u
+}
+
+// Küçük resim henüz üretilmemişse (backfill-variants çalıştırılmadıysa) orijinale döner.
+func thumbURL(filename string) string {
+	thumb := VariantName(filename, Variants[0])
+	if !exists(thumb) {
+		return imageURL(filename)
+	}
+	return imageURL(thumb)
+}
+
+// Mevcut varyantlardan bir srcset değeri oluşturur; varyant yoksa boş döner.
+func srcset(filename string) template.Srcset {
+	var parts []string
+	for _, v := range Variants {
+		name := VariantName(filename, v)
+		if !exists(name) {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %dw", imageURL(name), v.MaxSize))
+	}
+	return template.Srcset(strings.Join(parts, ", "))
+}
+
+// Şablonlar her sayfada varyantların varlığını sorar; uzak depolarda her seferinde istek
+// atmamak için sonuçlar önbelleğe alınır. Varyantlar silinmediğinden var olanlar kalıcı,
+// olmayanlar (başka bir süreç backfill çalıştırabilir) kısa süre saklanır.
+const missingTTL = time.Minute
+
+type existsEntry struct {
+	exists  bool
+	checked time.Time
+}
+
+var existence sync.Map
+
+func exists(key string) bool {
+	if v, ok := existence.Load(key); ok {
+		entry := v.(existsEntry)
+		if entry.exists || time.Since(entry.checked) < missingTTL {
+			return entry.exists
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err := Store.Stat(ctx, key)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return false // Geçici hatalar önbelleğe alınmaz
+	}
+	found := err == nil
+	existence.Store(key, existsEntry{exists: found, checked: time.Now()})
+	return found
+}
@@ -0,0 +1,147 @@
+package metrics // Prometheus metriklerini tanımlayan ve dışa aktaran paket
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "forum"
+
+var (
+	// Rota bazında HTTP istek sayısı
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	// Rota bazında HTTP istek süreleri
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// Veritabanı sorgu süreleri (select, insert, update, delete...)
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by statement type.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation"})
+
+	postsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "posts_created_total",
+		Help:      "Total number of posts created.",
+	})
+
+	commentsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "comments_created_total",
+		Help:      "Total number of comments created.",
+	})
+
+	votesCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "votes_created_total",
+		Help:      "Total number of votes cast, by target (post/comment) and type (like/dislike).",
+	}, []string{"target", "type"})
+
+	// Giriş denemeleri: method = password, google, github, facebook
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts by method and result.",
+	}, []string{"method", "result"})
+
+	uploadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upload_bytes_total",
+		Help:      "Total number of uploaded bytes by source.",
+	}, []string{"source"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequests, httpDuration, dbQueryDuration, postsCreated,
+		commentsCreated, votesCreated, logins, uploadBytes)
+}
+
+// /metrics uç noktası için Prometheus text formatında çıktı üreten işleyiciyi döndürür.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Bir işleyiciyi verilen rota etiketiyle sayaç ve gecikme metrikleriyle sarar.
+func InstrumentHandler(route string, next http.Handler) http.Handler {
+	labels := prometheus.Labels{"route": route}
+	counter := httpRequests.MustCurryWith(labels)
+	duration := httpDuration.MustCurryWith(labels)
+
+	return promhttp.InstrumentHandlerDuration(duration,
+		promhttp.InstrumentHandlerCounter(counter, next))
+}
+
+// Bir veritabanı sorgusunun süresini sorgu türüne göre kaydeder.
+func ObserveQuery(query string, start time.Time) {
+	dbQueryDuration.WithLabelValues(queryOperation(query)).Observe(time.Since(start).Seconds())
+}
+
+// Sorgunun ilk anahtar kelimesinden düşük kardinaliteli bir etiket üretir.
+func queryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "other"
+	}
+	switch op := strings.ToLower(fields[0]); op {
+	case "select", "insert", "update", "delete", "create", "alter", "pragma", "begin", "commit", "rollback":
+		return op
+	default:
+		return "other"
+	}
+}
+
+// Aktif oturum sayısını her kazımada (scrape) hesaplayan göstergeyi kaydeder.
+func RegisterActiveSessions(count func() float64) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_sessions",
+		Help:      "Number of sessions that have not expired yet.",
+	}, count))
+}
+
+func PostCreated() {
+	postsCreated.Inc()
+}
+
+func CommentCreated() {
+	commentsCreated.Inc()
+}
+
+// target: "post" veya "comment", voteType: 1 (like) veya -1 (dislike)
+func VoteCreated(target string, voteType int) {
+	kind := "like"
+	if voteType < 0 {
+		kind = "dislike"
+	}
+	votesCreated.WithLabelValues(target, kind).Inc()
+}
+
+// method: "password", "google", "github" veya "facebook"
+func Login(method string, success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	logins.WithLabelValues(method, result).Inc()
+}
+
+// source: "post", "comment" veya "file"
+func Uploaded(source string, n int64) {
+	uploadBytes.WithLabelValues(source).Add(float64(n))
+}
@@ -4,7 +4,6 @@ import (
 	"database/sql"  // Veritabanı işlemleri için
 	"encoding/json" // JSON verilerini işlemek için
 	"fmt"
-	"html/template"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,6 +11,8 @@ import (
 
 	// Formatlama ve çıktı işlemleri için
 	"form-project/datahandlers" // Veritabanı bağlantısı ve oturum yönetimi için
+	"form-project/media"        // Yükleme kotaları için
+	"form-project/render"       // Şablonları ortak düzenle işlemek için
 	"form-project/utils"        // Hata yönetimi gibi yardımcı fonksiyonlar için
 	// HTML şablonlarını işlemek için
 	// HTTP isteklerini ve yanıtlarını yönetmek için
@@ -43,6 +44,7 @@ type User struct {
 	Role               string
 	Password           sql.NullString // Google kayıtta şifre alanı gereksiz olabilir
 	ProfilePicturePath sql.NullString // Profil fotoğrafının yolu
+	UploadQuota        sql.NullInt64  // Kullanıcıya özel yükleme kotası (bayt); yoksa rolün kotası geçerlidir
 }
 
 // kullanıcının profil sayfasını oluşturur ve görüntüler.
@@ -55,56 +57,69 @@ func MyProfileHandler(w http.ResponseWriter, r *http.Request) {
 
 	user, err := getUserByID(session.UserID)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	ownPosts, err := getOwnPosts(session.UserID)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
 	likedPosts, err := getLikedPosts(session.UserID)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	tmpl, err := template.ParseFiles("templates/myprofil.html")
+	quota, err := media.QuotaFor(session.UserID)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	bookmarks, err := datahandlers.UserBookmarks(session.UserID, 0)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	folders, err := datahandlers.BookmarkFolders(session.UserID)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
 		return
 	}
 
 	data := struct {
+		render.Page
 		User       *User
 		OwnPosts   []Post
 		LikedPosts []Post
+		Quota      media.Quota
+		Bookmarks  []datahandlers.Bookmark // Yalnızca kullanıcının kendisine gösterilir
+		Folders    []datahandlers.BookmarkFolder
 	}{
+		Page:       render.NewPage(w, r),
 		User:       user,
 		OwnPosts:   ownPosts,
 		LikedPosts: likedPosts,
+		Quota:      quota,
+		Bookmarks:  bookmarks,
+		Folders:    folders,
 	}
 
-	err = tmpl.Execute(w, data)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-		return
+	if err := render.HTML(w, http.StatusOK, "myprofil", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
 // Belirtilen kullanıcı ID'sine ait gönderileri veritabanından çeker.
 func getOwnPosts(userID int) ([]Post, error) {
 	query := `SELECT posts.id, posts.user_id, posts.title, posts.content, posts.categories, posts.created_at, users.username,
-                     COALESCE(SUM(CASE WHEN votes.vote_type = 1 THEN 1 ELSE 0 END), 0) AS like_count,
-                     COALESCE(SUM(CASE WHEN votes.vote_type = -1 THEN 1 ELSE 0 END), 0) AS dislike_count,
-                     (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted = 0) AS comment_count
+                     posts.like_count, posts.dislike_count, posts.comment_count
               FROM posts
               JOIN users ON posts.user_id = users.id
-              LEFT JOIN votes ON votes.post_id = posts.id
               WHERE posts.user_id = ? AND posts.deleted = 0
-              GROUP BY posts.id
               ORDER BY posts.created_at DESC`
 
 	rows, err := datahandlers.DB.Query(query, userID)
@@ -135,15 +150,11 @@ func getOwnPosts(userID int) ([]Post, error) {
 func getLikedPosts(userID int) ([]Post, error) {
 	query := `
 		SELECT posts.id, posts.user_id, posts.title, posts.content, posts.categories, posts.created_at, users.username,
-		       COALESCE(SUM(CASE WHEN votes.vote_type = 1 THEN 1 ELSE 0 END), 0) AS like_count,
-		       COALESCE(SUM(CASE WHEN votes.vote_type = -1 THEN 1 ELSE 0 END), 0) AS dislike_count,
-		       (SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted = 0) AS comment_count
+		       posts.like_count, posts.dislike_count, posts.comment_count
 		FROM posts
 		JOIN users ON posts.user_id = users.id
-		LEFT JOIN votes ON votes.post_id = posts.id
 		WHERE posts.id IN (SELECT post_id FROM votes WHERE user_id = ? AND vote_type = 1)
 		AND posts.deleted = 0
-		GROUP BY posts.id, posts.user_id, posts.title, posts.content, posts.categories, posts.created_at, users.username
 		ORDER BY posts.created_at DESC`
 
 	rows, err := datahandlers.DB.Query(query, userID)
@@ -174,46 +185,55 @@ func EditUserHandler(w http.ResponseWriter, r *http.Request) {
 	userIDStr := strings.TrimPrefix(r.URL.Path, "/users/edit/")
 	userID, err := strconv.Atoi(userIDStr)
 	if err != nil {
-		utils.HandleErr(w, err, "Invalid user ID", http.StatusBadRequest)
+		utils.HandleErr(w, r, err, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
 
 	user, err := getUserByID(userID)
 	if err != nil {
-		utils.HandleErr(w, err, err.Error(), http.StatusNotFound)
+		utils.WriteError(w, r, err)
 		return
 	}
 
-	tmpl, err := template.ParseFiles("templates/edit_user.html")
+	quota, err := media.QuotaFor(userID)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	// Formda kota MB cinsinden gösterilir; boş alan rolün kotasını kullanır
+	quotaMB := ""
+	if user.UploadQuota.Valid {
+		quotaMB = strconv.FormatInt(user.UploadQuota.Int64>>20, 10)
+	}
+
 	data := struct {
-		User *User
+		render.Page
+		User    *User
+		Quota   media.Quota
+		QuotaMB string
 	}{
-		User: user,
+		Page:    render.NewPage(w, r),
+		User:    user,
+		Quota:   quota,
+		QuotaMB: quotaMB,
 	}
 
-	err = tmpl.Execute(w, data)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+	if err := render.HTML(w, http.StatusOK, "edit_user", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 	}
 }
 
 // Belirtilen kullanıcı ID'sine sahip kullanıcıyı veritabanından çeker.
 func getUserByID(userID int) (*User, error) {
 	var user User
-	query := "SELECT id, email, username, role FROM users WHERE id = ?"
-	err := datahandlers.DB.QueryRow(query, userID).Scan(&user.ID, &user.Email, &user.Username, &user.Role)
+	query := "SELECT id, email, username, role, upload_quota, profile_picture_path FROM users WHERE id = ?"
+	err := datahandlers.DB.QueryRow(query, userID).Scan(&user.ID, &user.Email, &user.Username, &user.Role, &user.UploadQuota, &user.ProfilePicturePath)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user with ID %d not found", userID)
+			return nil, utils.NotFound(fmt.Sprintf("user with ID %d not found", userID))
 		}
 		return nil, err
 	}
 	return &user, nil
 }
-
-const maxUploadSize = 20 * 1024 * 1024 // 20 MB
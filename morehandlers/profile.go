@@ -0,0 +1,334 @@
+package morehandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"form-project/badges"
+	"form-project/datahandlers"
+	"form-project/flash"
+	"form-project/media"
+	"form-project/render"
+	"form-project/reputation"
+	"form-project/utils"
+)
+
+// Profil alanlarının sınırları
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 500
+	maxLocationLength    = 100
+	maxProfileLinks      = 5
+	maxLinkLength        = 200
+	profileCommentLimit  = 20 // Profil sayfasında gösterilen son yorum sayısı
+)
+
+// PublicProfileHandler, /u/{username} adresindeki herkese açık profil sayfasını gösterir.
+func PublicProfileHandler(w http.ResponseWriter, r *http.Request) {
+	profile, err := datahandlers.ProfileByUsername(r.PathValue("username"))
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, utils.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+
+	posts, err := getOwnPosts(profile.ID)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	comments, err := datahandlers.UserComments(profile.ID, profileCommentLimit)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	score, err := datahandlers.Reputation(profile.ID)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+
+	awarded, err := badges.ForUser(profile.ID)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+
+	followers, following, err := datahandlers.FollowCounts(profile.ID)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+
+	page := render.NewPage(w, r)
+	isOwner := page.CurrentUser != nil && page.CurrentUser.ID == profile.ID
+	var privileges []reputation.Privilege
+	if isOwner {
+		if privileges, err = reputation.Privileges(profile.ID); err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+	}
+	blocked, followed := false, false
+	if page.CurrentUser != nil && !isOwner {
+		if blocked, err = datahandlers.HasBlocked(page.CurrentUser.ID, profile.ID); err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		if followed, err = datahandlers.IsFollowingUser(page.CurrentUser.ID, profile.ID); err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+	}
+
+	data := struct {
+		render.Page
+		Profile    *datahandlers.Profile
+		Posts      []Post
+		Comments   []datahandlers.UserComment
+		Reputation int
+		Privileges []reputation.Privilege // Yalnızca profil sahibine gösterilir
+		Badges     []badges.Awarded
+		IsOwner    bool
+		IsBlocked  bool // Oturumdaki kullanıcı bu kullanıcıyı engellemiş mi
+		IsFollowed bool // Oturumdaki kullanıcı bu kullanıcıyı takip ediyor mu
+		Followers  int
+		Following  int
+	}{
+		Page:       page,
+		Profile:    profile,
+		Posts:      posts,
+		Comments:   comments,
+		Reputation: score,
+		Privileges: privileges,
+		Badges:     awarded,
+		IsOwner:    isOwner,
+		IsBlocked:  blocked,
+		IsFollowed: followed,
+		Followers:  followers,
+		Following:  following,
+	}
+
+	if err := render.HTML(w, http.StatusOK, "profile", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// BlockUserHandler, /u/{username}/block adresinde kullanıcıyı engeller ya da ("action=unblock")
+// engeli kaldırır. Engellenen kullanıcıların bahsetmeleri bildirim oluşturmaz.
+func BlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	profile, err := datahandlers.ProfileByUsername(r.PathValue("username"))
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, utils.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	if profile.ID == session.UserID {
+		utils.WriteError(w, r, utils.BadRequest("You cannot block yourself", nil))
+		return
+	}
+
+	if r.FormValue("action") == "unblock" {
+		err = datahandlers.UnblockUser(session.UserID, profile.ID)
+		flash.AddSuccess(w, r, "@"+profile.Username+" has been unblocked.")
+	} else {
+		err = datahandlers.BlockUser(session.UserID, profile.ID)
+		flash.AddSuccess(w, r, "@"+profile.Username+" has been blocked. Their mentions will no longer notify you.")
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	http.Redirect(w, r, "/u/"+url.PathEscape(profile.Username), http.StatusSeeOther)
+}
+
+// FollowUserHandler, /u/{username}/follow adresinde kullanıcıyı takip eder ya da
+// ("action=unfollow") takibi bırakır. Takip edilen kullanıcıların yeni gönderileri bildirim oluşturur.
+func FollowUserHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	profile, err := datahandlers.ProfileByUsername(r.PathValue("username"))
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, utils.NotFound("User not found"))
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	if profile.ID == session.UserID {
+		utils.WriteError(w, r, utils.BadRequest("You cannot follow yourself", nil))
+		return
+	}
+
+	if r.FormValue("action") == "unfollow" {
+		err = datahandlers.UnfollowUser(session.UserID, profile.ID)
+		flash.AddSuccess(w, r, "You unfollowed @"+profile.Username+".")
+	} else {
+		err = datahandlers.FollowUser(session.UserID, profile.ID)
+		flash.AddSuccess(w, r, "You are now following @"+profile.Username+".")
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	http.Redirect(w, r, "/u/"+url.PathEscape(profile.Username), http.StatusSeeOther)
+}
+
+// EditProfileTemplateData, profil düzenleme formunun verisidir.
+type EditProfileTemplateData struct {
+	render.Page
+	Profile       *datahandlers.Profile
+	LinksText     string // Bağlantılar, formda satır satır
+	ErrorMessages map[string]string
+}
+
+// EditProfileHandler, oturumdaki kullanıcının profilini (görünen ad, hakkında, konum,
+// bağlantılar ve profil fotoğrafı) düzenler.
+func EditProfileHandler(w http.ResponseWriter, r *http.Request) {
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	profile, err := datahandlers.ProfileByID(session.UserID)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		renderEditProfile(w, r, http.StatusOK, profile, strings.Join(profile.Links, "\n"), nil)
+	case http.MethodPost:
+		updateProfile(w, r, profile)
+	default:
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+	}
+}
+
+func updateProfile(w http.ResponseWriter, r *http.Request, profile *datahandlers.Profile) {
+	r.Body = http.MaxBytesReader(w, r.Body, media.MaxFileSize()+1<<20)
+	if err := r.ParseMultipartForm(32 << 20); err != nil && err != http.ErrNotMultipart {
+		utils.WriteError(w, r, utils.BadRequest("The uploaded file is too large", err))
+		return
+	}
+
+	linksText := r.FormValue("links")
+	profile.DisplayName = strings.TrimSpace(r.FormValue("display_name"))
+	profile.Bio = strings.TrimSpace(strings.ReplaceAll(r.FormValue("bio"), "\r\n", "\n"))
+	profile.Location = strings.TrimSpace(r.FormValue("location"))
+	if privacy := r.FormValue("mention_privacy"); privacy != "" {
+		profile.MentionPrivacy = privacy
+	}
+	links, linkErr := parseLinks(linksText)
+	profile.Links = links
+
+	errorMessages := make(map[string]string)
+	if utf8.RuneCountInString(profile.DisplayName) > maxDisplayNameLength {
+		errorMessages["DisplayName"] = fmt.Sprintf("Display name can be at most %d characters.", maxDisplayNameLength)
+	}
+	if utf8.RuneCountInString(profile.Bio) > maxBioLength {
+		errorMessages["Bio"] = fmt.Sprintf("Bio can be at most %d characters.", maxBioLength)
+	}
+	if utf8.RuneCountInString(profile.Location) > maxLocationLength {
+		errorMessages["Location"] = fmt.Sprintf("Location can be at most %d characters.", maxLocationLength)
+	}
+	if linkErr != "" {
+		errorMessages["Links"] = linkErr
+	}
+	if !datahandlers.ValidMentionPrivacy(profile.MentionPrivacy) {
+		errorMessages["MentionPrivacy"] = "Please choose who can mention you."
+	}
+	if len(errorMessages) > 0 {
+		renderEditProfile(w, r, http.StatusBadRequest, profile, linksText, errorMessages)
+		return
+	}
+
+	// Yeni fotoğraf önce kaydedilir; doğrulama hatasında profil değişmeden kalır
+	avatar, err := media.AvatarFromForm(r, "avatar", profile.ID)
+	if err != nil {
+		utils.WriteError(w, r, err)
+		return
+	}
+
+	if err := datahandlers.UpdateProfile(*profile); err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	switch {
+	case avatar != nil:
+		err = datahandlers.SetAvatar(profile.ID, avatar.Filename)
+	case r.FormValue("remove_avatar") != "":
+		err = datahandlers.SetAvatar(profile.ID, "")
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+
+	flash.AddSuccess(w, r, "Your profile has been updated.")
+	http.Redirect(w, r, "/u/"+url.PathEscape(profile.Username), http.StatusSeeOther)
+}
+
+func renderEditProfile(w http.ResponseWriter, r *http.Request, status int, profile *datahandlers.Profile, linksText string, errorMessages map[string]string) {
+	data := EditProfileTemplateData{
+		Page:          render.NewPage(w, r),
+		Profile:       profile,
+		LinksText:     linksText,
+		ErrorMessages: errorMessages,
+	}
+	if err := render.HTML(w, status, "editProfile", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// Her satırda bir bağlantı bekler; boş satırlar atlanır. Yalnızca http ve https
+// adresleri kabul edilir. Hata varsa kullanıcıya gösterilecek mesajı döndürür.
+func parseLinks(text string) ([]string, string) {
+	var links []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if len(line) > maxLinkLength {
+			return links, fmt.Sprintf("Links can be at most %d characters.", maxLinkLength)
+		}
+		u, err := url.Parse(line)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return links, fmt.Sprintf("%q is not a valid http(s) link.", line)
+		}
+		links = append(links, u.String())
+	}
+	if len(links) > maxProfileLinks {
+		return links, fmt.Sprintf("You can add at most %d links.", maxProfileLinks)
+	}
+	return links, ""
+}
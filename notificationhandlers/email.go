@@ -0,0 +1,77 @@
+package notificationhandlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"form-project/datahandlers"
+	"form-project/notifications"
+	"form-project/render"
+	"form-project/utils"
+)
+
+// UnsubscribeTemplateData, e-posta aboneliğinden çıkma sayfasının verisidir.
+type UnsubscribeTemplateData struct {
+	render.Page
+	UserID    int
+	PostID    int64 // 0: tüm bildirim e-postaları
+	PostTitle string
+	Signature string
+	Done      bool
+}
+
+// UnsubscribeHandler, bildirim e-postalarındaki imzalı bağlantıyı karşılar; giriş gerektirmez.
+// GET onay sayfasını gösterir (e-posta istemcilerinin bağlantı önizlemeleri aboneliği
+// bozmasın diye), POST bir gönderinin ya da tüm bildirimlerin e-postalarını kapatır.
+func UnsubscribeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	userID, err := strconv.Atoi(r.FormValue("u"))
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequest("Invalid unsubscribe link", err))
+		return
+	}
+	postID, err := strconv.ParseInt(r.FormValue("p"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequest("Invalid unsubscribe link", err))
+		return
+	}
+	sig := r.FormValue("sig")
+	if !notifications.ValidUnsubscribe(userID, postID, sig) {
+		utils.WriteError(w, r, utils.Forbidden("This unsubscribe link is invalid"))
+		return
+	}
+
+	data := UnsubscribeTemplateData{
+		Page:      render.NewPage(w, r),
+		UserID:    userID,
+		PostID:    postID,
+		Signature: sig,
+	}
+	if postID != 0 {
+		data.PostTitle, err = datahandlers.PostTitle(postID)
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteError(w, r, utils.NotFound("This post no longer exists"))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		if err := datahandlers.UnsubscribeEmail(userID, postID); err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		data.Done = true
+	}
+	if err := render.HTML(w, http.StatusOK, "unsubscribe", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,176 @@
+package notificationhandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"form-project/datahandlers"
+	"form-project/flash"
+	"form-project/notifications"
+	"form-project/render"
+	"form-project/utils"
+)
+
+// Bildirim sayfasında gösterilen en fazla bildirim sayısı
+const notificationLimit = 50
+
+// PreferenceToggle, ayarlar formundaki bir bildirim tercihidir.
+type PreferenceToggle struct {
+	notifications.Preference
+	Enabled bool
+}
+
+// EmailModeOption, ayarlar formundaki e-posta sıklığı seçeneğidir.
+type EmailModeOption struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+// E-posta sıklığı seçenekleri, gösterilecek sırayla
+var emailModes = []EmailModeOption{
+	{Value: datahandlers.EmailOff, Label: "Never"},
+	{Value: datahandlers.EmailImmediate, Label: "As they happen"},
+	{Value: datahandlers.EmailDaily, Label: "Daily digest"},
+	{Value: datahandlers.EmailWeekly, Label: "Weekly digest"},
+}
+
+// NotificationsTemplateData, bildirim sayfasının verisidir.
+type NotificationsTemplateData struct {
+	render.Page
+	Notifications []datahandlers.Notification
+	Preferences   []PreferenceToggle
+	EmailEnabled  bool // Sunucuda e-posta gönderimi yapılandırılmış
+	EmailModes    []EmailModeOption
+}
+
+// NotificationsHandler, oturumdaki kullanıcının bildirimlerini ve bildirim tercihlerini gösterir.
+func NotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	list, err := datahandlers.UserNotifications(session.UserID, notificationLimit)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	prefs, err := datahandlers.NotificationPreferences(session.UserID)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	toggles := make([]PreferenceToggle, len(notifications.Preferences))
+	for i, p := range notifications.Preferences {
+		enabled, ok := prefs[p.Key]
+		toggles[i] = PreferenceToggle{Preference: p, Enabled: enabled || !ok}
+	}
+
+	mode, err := datahandlers.EmailMode(session.UserID)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	modes := make([]EmailModeOption, len(emailModes))
+	for i, m := range emailModes {
+		m.Selected = m.Value == mode
+		modes[i] = m
+	}
+
+	data := NotificationsTemplateData{
+		Page:          render.NewPage(w, r),
+		Notifications: list,
+		Preferences:   toggles,
+		EmailEnabled:  notifications.EmailEnabled(),
+		EmailModes:    modes,
+	}
+	if err := render.HTML(w, http.StatusOK, "notifications", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// MarkReadHandler, bir bildirimi ("id") ya da "all" gönderilmişse tüm bildirimleri okundu
+// olarak işaretler. fetch istekleri (Accept: application/json) okunmamış sayıyı JSON olarak alır.
+func MarkReadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		utils.WriteError(w, r, utils.Unauthorized("You need to log in to manage notifications"))
+		return
+	}
+
+	if r.FormValue("all") != "" {
+		err = datahandlers.MarkAllNotificationsRead(session.UserID)
+	} else {
+		id, convErr := strconv.ParseInt(r.FormValue("id"), 10, 64)
+		if convErr != nil {
+			utils.WriteError(w, r, utils.BadRequest("Invalid notification ID", convErr))
+			return
+		}
+		err = datahandlers.MarkNotificationRead(session.UserID, id)
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	notifications.UnreadChanged(session.UserID)
+
+	if utils.WantsJSON(r) {
+		unread, err := datahandlers.UnreadNotificationCount(session.UserID)
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int{"unread": unread})
+		return
+	}
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
+
+// PreferencesHandler, bildirim tercihlerini ve e-posta sıklığını kaydeder; işaretlenmeyen
+// tercihler kapatılır.
+func PreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		utils.WriteError(w, r, utils.BadRequest("Invalid form", err))
+		return
+	}
+
+	for _, p := range notifications.Preferences {
+		if err := datahandlers.SetNotificationPreference(session.UserID, p.Key, r.PostForm.Get(p.Key) != ""); err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+	}
+	if mode := r.PostForm.Get("email_mode"); mode != "" && notifications.EmailEnabled() {
+		if !datahandlers.ValidEmailMode(mode) {
+			utils.WriteError(w, r, utils.BadRequest("Invalid email frequency", nil))
+			return
+		}
+		if err := datahandlers.SetEmailMode(session.UserID, mode); err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+	}
+	flash.AddSuccess(w, r, "Notification settings saved.")
+	http.Redirect(w, r, "/notifications", http.StatusSeeOther)
+}
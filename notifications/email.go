@@ -0,0 +1,231 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"form-project/datahandlers"
+	"form-project/mail"
+	"form-project/security"
+)
+
+var (
+	mailer  mail.Mailer
+	baseURL string
+	// Aynı anda tek teslim turu çalışır; aksi halde bir bildirim iki kez gönderilebilir
+	deliveryMu sync.Mutex
+)
+
+// SetMailer, bildirim e-postalarını gönderecek Mailer'ı ve bağlantılarda kullanılacak
+// sitenin adresini ayarlar. m nil ise e-posta gönderilmez.
+func SetMailer(m mail.Mailer, base string) {
+	mailer = m
+	baseURL = strings.TrimRight(base, "/")
+}
+
+// EmailEnabled, e-posta gönderiminin yapılandırılıp yapılandırılmadığını döndürür.
+func EmailEnabled() bool {
+	return mailer != nil
+}
+
+// EmailReport, bir teslim turunun özetidir.
+type EmailReport struct {
+	Recipients    int      // Bekleyen bildirimi olan kullanıcılar
+	Sent          int      // Gönderilen e-postalar
+	Notifications int      // E-postalara giren bildirimler
+	Failed        []string // Gönderilemeyen alıcılar ve hataları
+}
+
+// Kullanıcının modu için sıradaki e-postanın zamanı gelip gelmediği
+func digestDue(r datahandlers.EmailRecipient, now time.Time) bool {
+	var period time.Duration
+	switch r.Mode {
+	case datahandlers.EmailImmediate:
+		return true
+	case datahandlers.EmailDaily:
+		period = 24 * time.Hour
+	case datahandlers.EmailWeekly:
+		period = 7 * 24 * time.Hour
+	default:
+		return false
+	}
+	return !r.DigestAt.Valid || now.Sub(r.DigestAt.Time) >= period
+}
+
+// SendEmails, zamanı gelen kullanıcılara bekleyen bildirimlerini tek bir e-postada gönderir.
+// Gönderilemeyen bildirimler bir sonraki tura kalır.
+func SendEmails(ctx context.Context, now time.Time) (EmailReport, error) {
+	var report EmailReport
+	if mailer == nil {
+		return report, nil
+	}
+	deliveryMu.Lock()
+	defer deliveryMu.Unlock()
+
+	recipients, err := datahandlers.EmailRecipients()
+	if err != nil {
+		return report, err
+	}
+	report.Recipients = len(recipients)
+	for _, r := range recipients {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if !digestDue(r, now) {
+			continue
+		}
+		pending, err := datahandlers.PendingEmailNotifications(r.UserID)
+		if err != nil {
+			return report, err
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		if err := mailer.Send(ctx, digestMessage(r, pending)); err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", r.Email, err))
+			continue
+		}
+
+		ids := make([]int64, len(pending))
+		for i, n := range pending {
+			ids[i] = n.ID
+		}
+		if err := datahandlers.MarkNotificationsEmailed(ids); err != nil {
+			return report, err
+		}
+		if err := datahandlers.SetEmailDigestSent(r.UserID, now); err != nil {
+			return report, err
+		}
+		report.Sent++
+		report.Notifications += len(pending)
+	}
+	return report, nil
+}
+
+// StartEmailDelivery, SendEmails'i arka planda her interval'de bir çalıştırır. interval sıfırsa
+// veya e-posta yapılandırılmamışsa çalışmaz.
+func StartEmailDelivery(interval time.Duration) {
+	if interval <= 0 || mailer == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			report, err := SendEmails(ctx, now)
+			cancel()
+			if err != nil {
+				log.Printf("notification email: %v", err)
+			}
+			if report.Sent > 0 || len(report.Failed) > 0 {
+				log.Printf("notification email: sent %d emails with %d notifications, %d failed",
+					report.Sent, report.Notifications, len(report.Failed))
+			}
+			for _, failure := range report.Failed {
+				log.Printf("notification email: failed %s", failure)
+			}
+		}
+	}()
+}
+
+// Bildirimleri gönderiye göre gruplayan düz metin e-posta. Her konu için o konudan,
+// en sonda da tüm e-postalardan çıkma bağlantısı bulunur.
+func digestMessage(r datahandlers.EmailRecipient, pending []datahandlers.Notification) mail.Message {
+	var groups [][]datahandlers.Notification
+	index := make(map[int64]int)
+	for _, n := range pending {
+		i, ok := index[n.PostID]
+		if !ok {
+			i = len(groups)
+			index[n.PostID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], n)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hi %s,\n\n", r.Username)
+	if len(pending) == 1 {
+		b.WriteString("You have a new notification:\n")
+	} else {
+		fmt.Fprintf(&b, "You have %d new notifications:\n", len(pending))
+	}
+	for _, group := range groups {
+		title := group[0].PostTitle
+		if title == "" {
+			title = "Other"
+		}
+		fmt.Fprintf(&b, "\n== %s ==\n\n", title)
+		for _, n := range group {
+			fmt.Fprintf(&b, "- %s (%s)\n", describe(n), n.CreatedAt.Format("2006-01-02 15:04"))
+			if link := n.URL(); link != "" {
+				fmt.Fprintf(&b, "  %s\n", baseURL+link)
+			}
+		}
+		if postID := group[0].PostID; postID != 0 {
+			fmt.Fprintf(&b, "\nStop emails about this post: %s\n", UnsubscribeURL(r.UserID, postID))
+		}
+	}
+	fmt.Fprintf(&b, "\n--\nChange how often you get these emails: %s/notifications\n", baseURL)
+	fmt.Fprintf(&b, "Unsubscribe from all notification emails: %s\n", UnsubscribeURL(r.UserID, 0))
+
+	subject := fmt.Sprintf("%d new notifications", len(pending))
+	switch {
+	case r.Mode == datahandlers.EmailDaily:
+		subject = "Your daily digest: " + subject
+	case r.Mode == datahandlers.EmailWeekly:
+		subject = "Your weekly digest: " + subject
+	case len(pending) == 1:
+		subject = describe(pending[0])
+	}
+	return mail.Message{
+		To:      r.Email,
+		Subject: subject,
+		Body:    b.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":         "<" + UnsubscribeURL(r.UserID, 0) + ">",
+			"Auto-Submitted":           "auto-generated",
+			"X-Auto-Response-Suppress": "All",
+		},
+	}
+}
+
+// Bildirimin e-postadaki cümlesi; moderatör işlemlerinde işlemi yapan gösterilmez.
+func describe(n datahandlers.Notification) string {
+	actor := n.ActorUsername
+	if n.Moderation() || actor == "" {
+		actor = "A moderator"
+	}
+	if n.PostTitle != "" {
+		return fmt.Sprintf("%s %s %q", actor, n.Verb(), n.PostTitle)
+	}
+	return actor + " " + n.Verb()
+}
+
+// Abonelikten çıkma bağlantısının imzaladığı değer
+func unsubscribeValue(userID int, postID int64) string {
+	return fmt.Sprintf("unsubscribe:%d:%d", userID, postID)
+}
+
+// UnsubscribeURL, kullanıcının giriş yapmadan gönderinin (postID 0 ise tüm) bildirim
+// e-postalarından çıkmasını sağlayan imzalı bağlantıdır.
+func UnsubscribeURL(userID int, postID int64) string {
+	q := url.Values{
+		"u":   {strconv.Itoa(userID)},
+		"p":   {strconv.FormatInt(postID, 10)},
+		"sig": {security.Signature(unsubscribeValue(userID, postID))},
+	}
+	return baseURL + "/email/unsubscribe?" + q.Encode()
+}
+
+// ValidUnsubscribe, abonelikten çıkma bağlantısının imzasını doğrular.
+func ValidUnsubscribe(userID int, postID int64, sig string) bool {
+	return security.ValidSignature(unsubscribeValue(userID, postID), sig)
+}
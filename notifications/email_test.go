@@ -0,0 +1,223 @@
+package notifications
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	netmail "net/mail"
+
+	"form-project/config"
+	"form-project/datahandlers"
+	"form-project/mail"
+	"form-project/security"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	security.SetSigningKey([]byte("test signing key"))
+	SetMailer(nil, "http://forum.test/")
+
+	link, err := url.Parse(UnsubscribeURL(7, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := link.Scheme + "://" + link.Host + link.Path; got != "http://forum.test/email/unsubscribe" {
+		t.Errorf("UnsubscribeURL points to %q", got)
+	}
+	q := link.Query()
+	userID, _ := strconv.Atoi(q.Get("u"))
+	postID, _ := strconv.ParseInt(q.Get("p"), 10, 64)
+	sig := q.Get("sig")
+	if userID != 7 || postID != 42 || sig == "" {
+		t.Fatalf("UnsubscribeURL query = %v", q)
+	}
+	if !ValidUnsubscribe(userID, postID, sig) {
+		t.Error("ValidUnsubscribe rejected its own token")
+	}
+
+	tests := []struct {
+		name   string
+		userID int
+		postID int64
+		sig    string
+	}{
+		{"other user", 8, 42, sig},
+		{"other post", 7, 43, sig},
+		{"all posts", 7, 0, sig},
+		{"empty signature", 7, 42, ""},
+		{"altered signature", 7, 42, strings.ToUpper(sig)},
+		{"post token for all posts", 7, 0, security.Signature(unsubscribeValue(7, 42))},
+	}
+	for _, tt := range tests {
+		if ValidUnsubscribe(tt.userID, tt.postID, tt.sig) {
+			t.Errorf("%s: ValidUnsubscribe(%d, %d, %q) = true", tt.name, tt.userID, tt.postID, tt.sig)
+		}
+	}
+}
+
+// Tüm tabloları ve geçişleri içeren geçici bir veritabanı açar
+func openTestDB(t *testing.T) {
+	t.Helper()
+	db, err := datahandlers.Open(filepath.Join(t.TempDir(), "forum.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := datahandlers.DB
+	datahandlers.DB = db
+	t.Cleanup(func() {
+		datahandlers.DB = old
+		db.Close()
+	})
+	datahandlers.CreateTables()
+	if err := datahandlers.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Outbox'a önceki çağrıdan bu yana yazılan e-postaların alıcı ve konuları
+type outbox struct {
+	t    *testing.T
+	dir  string
+	seen map[string]bool
+}
+
+func (o *outbox) drain() map[string]string {
+	o.t.Helper()
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		o.t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, e := range entries {
+		if o.seen[e.Name()] {
+			continue
+		}
+		o.seen[e.Name()] = true
+		f, err := os.Open(filepath.Join(o.dir, e.Name()))
+		if err != nil {
+			o.t.Fatal(err)
+		}
+		msg, err := netmail.ReadMessage(f)
+		f.Close()
+		if err != nil {
+			o.t.Fatalf("%s: %v", e.Name(), err)
+		}
+		to, err := netmail.ParseAddress(msg.Header.Get("To"))
+		if err != nil {
+			o.t.Fatalf("%s: %v", e.Name(), err)
+		}
+		if _, dup := got[to.Address]; dup {
+			o.t.Errorf("more than one email to %s in one run", to.Address)
+		}
+		got[to.Address] = msg.Header.Get("Subject")
+	}
+	return got
+}
+
+func TestSendEmailsDigests(t *testing.T) {
+	security.SetSigningKey([]byte("test signing key"))
+	openTestDB(t)
+	dir := t.TempDir()
+	m, err := mail.New(config.Mail{Driver: "file", Dir: dir, From: "forum@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetMailer(m, "http://forum.test")
+	t.Cleanup(func() { SetMailer(nil, "") })
+	box := &outbox{t: t, dir: dir, seen: make(map[string]bool)}
+
+	users := map[string]string{
+		"actor":    datahandlers.EmailOff,
+		"instant":  datahandlers.EmailImmediate,
+		"daily":    datahandlers.EmailDaily,
+		"weekly":   datahandlers.EmailWeekly,
+		"nomail":   datahandlers.EmailOff,
+		"unsubbed": datahandlers.EmailImmediate,
+	}
+	ids := make(map[string]int)
+	for name, mode := range users {
+		res, err := datahandlers.DB.Exec("INSERT INTO users (email, username, password, email_mode) VALUES (?, ?, 'x', ?)",
+			name+"@example.com", name, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, _ := res.LastInsertId()
+		ids[name] = int(id)
+	}
+	res, err := datahandlers.DB.Exec("INSERT INTO posts (user_id, title, content, created_at) VALUES (?, 'Hello', 'x', ?)",
+		ids["actor"], time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	postID, _ := res.LastInsertId()
+	if err := datahandlers.UnsubscribeEmail(ids["unsubbed"], postID); err != nil {
+		t.Fatal(err)
+	}
+
+	notify := func(names ...string) {
+		t.Helper()
+		for _, name := range names {
+			err := datahandlers.CreateNotification(datahandlers.Notification{
+				UserID: ids[name], ActorID: ids["actor"], Type: datahandlers.NotificationComment, PostID: postID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	send := func(now time.Time, want map[string]string) {
+		t.Helper()
+		if _, err := SendEmails(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+		got := box.drain()
+		var gotTo, wantTo []string
+		for to := range got {
+			gotTo = append(gotTo, to)
+		}
+		for to, subject := range want {
+			wantTo = append(wantTo, to)
+			if got[to] != subject {
+				t.Errorf("%s: subject to %s = %q, want %q", now.Format(time.RFC3339), to, got[to], subject)
+			}
+		}
+		sort.Strings(gotTo)
+		sort.Strings(wantTo)
+		if strings.Join(gotTo, ",") != strings.Join(wantTo, ",") {
+			t.Errorf("%s: emailed %v, want %v", now.Format(time.RFC3339), gotTo, wantTo)
+		}
+	}
+
+	start := time.Now()
+	// İlk turda özet zamanı kaydı olmayan herkes bekleyenleri alır
+	notify("instant", "daily", "daily", "weekly", "nomail", "unsubbed")
+	send(start, map[string]string{
+		"instant@example.com": `actor commented on your post "Hello"`,
+		"daily@example.com":   "Your daily digest: 2 new notifications",
+		"weekly@example.com":  "Your weekly digest: 1 new notifications",
+	})
+
+	// Süresi dolmayan özetler bekler; anlık mod hemen gönderir
+	notify("instant", "daily", "weekly", "weekly")
+	send(start.Add(time.Hour), map[string]string{
+		"instant@example.com": `actor commented on your post "Hello"`,
+	})
+	send(start.Add(23*time.Hour), nil)
+
+	// Bekleyenler tek bir özette toplanır
+	notify("daily", "weekly")
+	send(start.Add(24*time.Hour), map[string]string{
+		"daily@example.com": "Your daily digest: 2 new notifications",
+	})
+	send(start.Add(6*24*time.Hour), nil)
+	send(start.Add(7*24*time.Hour), map[string]string{
+		"weekly@example.com": "Your weekly digest: 3 new notifications",
+	})
+	send(start.Add(8*24*time.Hour), nil)
+}
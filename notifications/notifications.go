@@ -0,0 +1,216 @@
+package notifications // Gönderi, yorum, oy ve moderasyon olaylarından uygulama içi bildirim üreten servis
+
+import (
+	"fmt"
+
+	"form-project/datahandlers"
+	"form-project/events"
+)
+
+// Preference, kullanıcıların bildirim ayarlarından açıp kapatabildiği bir bildirim grubudur.
+type Preference struct {
+	Key   string
+	Label string
+	Types []string // Bu tercihe bağlı bildirim türleri
+}
+
+// Preferences, ayarlar sayfasında gösterilen sırayla tüm tercihlerdir.
+var Preferences = []Preference{
+	{"comment", "Comments on my posts", []string{datahandlers.NotificationComment}},
+	{"reply", "New comments on posts I follow", []string{datahandlers.NotificationReply}},
+	{"mention", "Mentions of my @username", []string{datahandlers.NotificationMention}},
+	{"vote", "Likes and dislikes on my posts and comments", []string{datahandlers.NotificationVote}},
+	{"accepted", "My answers accepted on questions", []string{datahandlers.NotificationAcceptedAnswer}},
+	{"following", "New posts from users and categories I follow", []string{
+		datahandlers.NotificationFollowedUser, datahandlers.NotificationFollowedCategory}},
+	{"moderation", "Moderator actions on my posts and reports", []string{
+		datahandlers.NotificationPostRemoved, datahandlers.NotificationReportResolved}},
+}
+
+// Bildirim türünün bağlı olduğu tercih
+func preferenceKey(notificationType string) string {
+	for _, p := range Preferences {
+		for _, t := range p.Types {
+			if t == notificationType {
+				return p.Key
+			}
+		}
+	}
+	return notificationType
+}
+
+// Publish, bildirimi alıcının tercihlerine göre kaydeder. Kullanıcının kendi işlemleri,
+// kapatılmış türler, taraflardan birinin diğerini engellediği durumlar ve aynı olay için
+// henüz okunmamış bir bildirim varsa yeni bildirim oluşturulmaz.
+func Publish(n datahandlers.Notification) error {
+	if n.UserID == 0 || n.UserID == n.ActorID {
+		return nil
+	}
+	enabled, err := datahandlers.NotificationEnabled(n.UserID, preferenceKey(n.Type))
+	if err != nil || !enabled {
+		return err
+	}
+	if n.ActorID != 0 && !n.Moderation() {
+		blocked, err := datahandlers.EitherBlocked(n.ActorID, n.UserID)
+		if err != nil || blocked {
+			return err
+		}
+	}
+	exists, err := datahandlers.HasUnreadNotification(n)
+	if err != nil || exists {
+		return err
+	}
+	if err := datahandlers.CreateNotification(n); err != nil {
+		return err
+	}
+	UnreadChanged(n.UserID)
+	return nil
+}
+
+// UnreadChanged, kullanıcının açık sekmelerine güncel okunmamış bildirim sayısını gönderir.
+func UnreadChanged(userID int) {
+	unread, err := datahandlers.UnreadNotificationCount(userID)
+	if err != nil {
+		return
+	}
+	events.Publish(events.UserTopic(userID), "notifications", map[string]int{"unread": unread})
+}
+
+// PostCreated, yeni gönderide bahsedilen kullanıcılara, yazarı takip edenlere ve gönderinin
+// kategorilerinden birini takip edenlere bildirim gönderir. Her kullanıcı en fazla bir bildirim alır.
+func PostCreated(authorID int, postID int64, categories []string, mentioned []datahandlers.MentionedUser) error {
+	notified := map[int]bool{authorID: true}
+	if err := notifyMentioned(authorID, postID, 0, mentioned, notified); err != nil {
+		return err
+	}
+
+	followers, err := datahandlers.UserFollowers(authorID)
+	if err != nil {
+		return err
+	}
+	for _, userID := range followers {
+		if notified[userID] {
+			continue
+		}
+		notified[userID] = true
+		if err := Publish(datahandlers.Notification{UserID: userID, ActorID: authorID,
+			Type: datahandlers.NotificationFollowedUser, PostID: postID}); err != nil {
+			return err
+		}
+	}
+
+	for _, category := range categories {
+		followers, err := datahandlers.CategoryFollowers(category)
+		if err != nil {
+			return err
+		}
+		for _, userID := range followers {
+			if notified[userID] {
+				continue
+			}
+			notified[userID] = true
+			if err := Publish(datahandlers.Notification{UserID: userID, ActorID: authorID,
+				Type: datahandlers.NotificationFollowedCategory, PostID: postID, Message: category}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CommentCreated, yeni yorum için bahsedilen kullanıcılara, gönderi sahibine ve gönderiyi
+// takip eden kullanıcılara bildirim gönderir. Her kullanıcı en fazla bir bildirim alır.
+func CommentCreated(authorID int, postID, commentID int64, mentioned []datahandlers.MentionedUser) error {
+	notified := map[int]bool{authorID: true}
+	if err := notifyMentioned(authorID, postID, commentID, mentioned, notified); err != nil {
+		return err
+	}
+
+	var ownerID int
+	if err := datahandlers.DB.QueryRow("SELECT user_id FROM posts WHERE id = ?", postID).Scan(&ownerID); err != nil {
+		return fmt.Errorf("error finding owner of post %d: %v", postID, err)
+	}
+	if !notified[ownerID] {
+		notified[ownerID] = true
+		if err := Publish(datahandlers.Notification{UserID: ownerID, ActorID: authorID,
+			Type: datahandlers.NotificationComment, PostID: postID, CommentID: commentID}); err != nil {
+			return err
+		}
+	}
+
+	followers, err := datahandlers.PostFollowers(postID)
+	if err != nil {
+		return err
+	}
+	for _, userID := range followers {
+		if notified[userID] {
+			continue
+		}
+		notified[userID] = true
+		if err := Publish(datahandlers.Notification{UserID: userID, ActorID: authorID,
+			Type: datahandlers.NotificationReply, PostID: postID, CommentID: commentID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bahsetmeleri kapatmamış kullanıcılara bahsetme bildirimi gönderir ve onları notified'a ekler.
+func notifyMentioned(authorID int, postID, commentID int64, mentioned []datahandlers.MentionedUser, notified map[int]bool) error {
+	for _, u := range mentioned {
+		if u.Privacy == datahandlers.MentionsNobody {
+			continue
+		}
+		if notified != nil {
+			notified[u.ID] = true
+		}
+		if err := Publish(datahandlers.Notification{UserID: u.ID, ActorID: authorID,
+			Type: datahandlers.NotificationMention, PostID: postID, CommentID: commentID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Voted, oylanan gönderi veya yorumun (commentID sıfır değilse yorum) sahibine bildirim gönderir.
+func Voted(voterID int, postID, commentID int64, voteType int) error {
+	var ownerID int
+	var err error
+	if commentID != 0 {
+		err = datahandlers.DB.QueryRow("SELECT user_id, post_id FROM comments WHERE id = ?", commentID).Scan(&ownerID, &postID)
+	} else {
+		err = datahandlers.DB.QueryRow("SELECT user_id FROM posts WHERE id = ?", postID).Scan(&ownerID)
+	}
+	if err != nil {
+		return fmt.Errorf("error finding owner of voted content: %v", err)
+	}
+
+	message := "like"
+	if voteType < 0 {
+		message = "dislike"
+	}
+	return Publish(datahandlers.Notification{UserID: ownerID, ActorID: voterID,
+		Type: datahandlers.NotificationVote, PostID: postID, CommentID: commentID, Message: message})
+}
+
+// AnswerAccepted, yorumu sorunun cevabı olarak kabul edilen kullanıcıya bildirim gönderir.
+func AnswerAccepted(actorID, answerAuthorID int, postID, commentID int64) error {
+	return Publish(datahandlers.Notification{UserID: answerAuthorID, ActorID: actorID,
+		Type: datahandlers.NotificationAcceptedAnswer, PostID: postID, CommentID: commentID})
+}
+
+// PostRemoved, bir moderatörün sildiği gönderinin sahibine ve gönderiyi bildiren kullanıcılara
+// bildirim gönderir. Gönderi artık olmadığından başlığı bildirimde saklanır.
+func PostRemoved(moderatorID, authorID int, title string, reporters []int) error {
+	if err := Publish(datahandlers.Notification{UserID: authorID, ActorID: moderatorID,
+		Type: datahandlers.NotificationPostRemoved, Message: title}); err != nil {
+		return err
+	}
+	for _, userID := range reporters {
+		if err := Publish(datahandlers.Notification{UserID: userID, ActorID: moderatorID,
+			Type: datahandlers.NotificationReportResolved, Message: title}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
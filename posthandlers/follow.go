@@ -0,0 +1,51 @@
+package posthandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"form-project/datahandlers"
+	"form-project/flash"
+	"form-project/utils"
+)
+
+// FollowPostHandler, /posts/follow/{id} adresinde gönderiyi takip eder ya da ("action=unfollow")
+// takibi bırakır. Takip edilen gönderilere gelen yorumlar bildirim oluşturur.
+func FollowPostHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	postID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequest("Invalid post ID", err))
+		return
+	}
+	if _, err := datahandlers.PostTitle(postID); err == sql.ErrNoRows {
+		utils.WriteError(w, r, utils.NotFound("Post not found"))
+		return
+	} else if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+
+	if r.FormValue("action") == "unfollow" {
+		err = datahandlers.UnfollowPost(session.UserID, postID)
+		flash.AddSuccess(w, r, "You will no longer be notified about new comments on this post.")
+	} else {
+		err = datahandlers.FollowPost(session.UserID, postID)
+		flash.AddSuccess(w, r, "You are now following this post.")
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/viewPost?id=%d", postID), http.StatusSeeOther)
+}
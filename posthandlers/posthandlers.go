@@ -5,21 +5,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
-	"io"
 	"log"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
+	"form-project/badges"
 	"form-project/datahandlers"
+	"form-project/events"
+	"form-project/flash"
 	"form-project/homehandlers"
+	"form-project/media"
+	"form-project/metrics"
+	"form-project/notifications"
+	"form-project/render"
+	"form-project/reputation"
 	"form-project/utils"
+)
 
-	"github.com/google/uuid"
+// Bir gönderiye ve bir yoruma eklenebilecek en fazla dosya sayısı
+const (
+	maxPostAttachments    = 10
+	maxCommentAttachments = 4
 )
 
 type Post struct {
@@ -35,7 +43,14 @@ type Post struct {
 	DislikeCount        int
 	Username            string
 	CommentCount        int
+	ContentHTML         template.HTML // Markdown'dan işlenmiş içerik
 	ImagePath           string
+	AvatarPath          string // Yazarın profil fotoğrafı; yoksa boş
+	AuthorReputation    int    // Yazarın puanı
+	IsQuestion          bool
+	AcceptedCommentID   int64 // Kabul edilmiş cevap yoksa 0
+	Attachments         []datahandlers.Attachment
+	BookmarkID          int64 // Oturumdaki kullanıcı gönderiyi kaydetmişse yer iminin ID'si
 }
 
 type Comment struct {
@@ -43,16 +58,23 @@ type Comment struct {
 	PostID             int
 	UserID             int
 	Content            string
+	ContentHTML        template.HTML // Markdown'dan işlenmiş içerik
 	CreatedAt          time.Time
 	CreatedAtFormatted string
 	LikeCount          int
 	DislikeCount       int
 	Username           string // Kullanıcı adı
 	ImagePath          string // Add this line to include ImagePath
+	AvatarPath         string // Yorumu yazanın profil fotoğrafı; yoksa boş
+	AuthorReputation   int    // Yorumu yazanın puanı
+	Accepted           bool   // Sorunun kabul edilmiş cevabı
+	Attachments        []datahandlers.Attachment
+	BookmarkID         int64 // Oturumdaki kullanıcı yorumu kaydetmişse yer iminin ID'si
+
+	cachedHTML    string // Önbellekteki HTML ve sürümü
+	cachedVersion int
 }
 
-const maxUploadSize = 20 * 1024 * 1024 // 20 MB
-
 func CreatePostHandler(w http.ResponseWriter, r *http.Request) {
 	session, err := datahandlers.GetSession(r)
 	if err != nil || session == nil {
@@ -61,116 +83,75 @@ func CreatePostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Method == http.MethodPost {
+		r.Body = http.MaxBytesReader(w, r.Body, media.MaxRequestSize())
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			utils.WriteError(w, r, utils.BadRequest("The attached files are too large", err))
+			return
+		}
+
 		// Form verilerini al
 		title := r.FormValue("title")
 		content := r.FormValue("content")
 		categoriesJSON := r.FormValue("categories")
+		isQuestion := r.FormValue("question") == "1"
 
 		// Kategorileri JSON'dan ayrıştır
 		var categories []string
 		err := json.Unmarshal([]byte(categoriesJSON), &categories)
 		if err != nil {
-			utils.HandleErr(w, err, "Invalid categories format", http.StatusBadRequest)
+			utils.HandleErr(w, r, err, "Invalid categories format", http.StatusBadRequest)
 			return
 		}
 
-		// Fotoğrafı işle
-		var imagePath string
-		newFilename := ""
-
-		file, handler, err := r.FormFile("image")
-		if err != nil && err != http.ErrMissingFile {
-			utils.HandleErr(w, err, "Error getting image", http.StatusBadRequest)
+		// Ekleri formdaki sırasıyla işle (varsa)
+		attachments, err := media.FromFormFiles(r, "attachments", "captions", "post", session.UserID, maxPostAttachments)
+		if err != nil {
+			utils.WriteError(w, r, err)
 			return
 		}
-
-		if file != nil { // Dosya varsa işle
-			defer file.Close()
-
-			// Dosya boyutunu kontrol et
-			if handler.Size > maxUploadSize {
-				utils.HandleErr(w, fmt.Errorf("file size exceeds limit"), "File size exceeds limit (20MB)", http.StatusBadRequest)
-				return
-			}
-
-			// Dosya uzantısını kontrol et
-			ext := filepath.Ext(handler.Filename)
-			allowedExtensions := map[string]bool{
-				".jpg":  true,
-				".jpeg": true,
-				".png":  true,
-				".gif":  true,
-			}
-			if !allowedExtensions[ext] {
-				utils.HandleErr(w, fmt.Errorf("unsupported image format: %s", ext), "Unsupported image format", http.StatusBadRequest)
-				return
-			}
-
-			// Benzersiz dosya adı oluştur ve newFilename'e ata
-			imageUUID := uuid.New().String()
-			newFilename = imageUUID + ext
-
-			// uploads dizininin var olup olmadığını kontrol et, yoksa oluştur
-			uploadsDir := "./uploads"
-			if _, err := os.Stat(uploadsDir); os.IsNotExist(err) {
-				os.Mkdir(uploadsDir, 0o755) // Klasörü oluştur
-			}
-
-			// Kaydedilecek dosyanın tam yolunu oluştur
-			imagePath = filepath.Join(uploadsDir, newFilename)
-
-			// Fotoğrafı kaydet
-			if err := saveImage(file, imagePath); err != nil {
-				utils.HandleErr(w, err, "Error saving image", http.StatusInternalServerError)
-				return
-			}
-		}
+		links, imageFilename := attachmentLinks(attachments)
 
 		categoriesData, err := json.Marshal(categories)
 		if err != nil {
-			utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+			utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 			return
 		}
 
-		// Veritabanına kaydet (imagePath ile birlikte)
-		_, err = datahandlers.DB.Exec("INSERT INTO posts (user_id, title, content, categories, created_at, image_path) VALUES (?, ?, ?, ?, ?, ?)",
-			session.UserID, title, content, string(categoriesData), time.Now(), newFilename)
+		// Gönderiyi ve eklerini tek işlemde kaydet
+		postID, err := datahandlers.CreatePost(datahandlers.NewPost{
+			UserID: session.UserID, Title: title, Content: content, Categories: string(categoriesData),
+			ImagePath: imageFilename, IsQuestion: isQuestion, Attachments: links,
+		})
 		if err != nil {
-			utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+			utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 			return
 		}
+		// Gönderi kaydedildi; takip, bahsetmeler ve bildirimler kaydedilemezse yalnızca günlüğe yazılır
+		mentioned, err := datahandlers.RecordMentions(session.UserID, postID, 0, content)
+		if err == nil {
+			err = datahandlers.AutoFollowPost(session.UserID, postID)
+		}
+		if err == nil {
+			err = notifications.PostCreated(session.UserID, postID, categories, mentioned)
+		}
+		if err != nil {
+			log.Printf("post %d: %v", postID, err)
+		}
+		if err := datahandlers.RefreshPostScores(postID); err != nil {
+			log.Printf("post %d scores: %v", postID, err)
+		}
+		metrics.PostCreated()
+		badges.Trigger(session.UserID, badges.PostCreated)
+		events.Publish(events.FeedTopic, "post", map[string]int64{"id": postID})
 
+		flash.AddSuccess(w, r, "Post created.")
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 
-	tmpl, err := template.ParseFiles("templates/createPost.html")
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-	err = tmpl.Execute(w, nil)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
-	}
-}
-
-// Fotoğrafı kaydet
-func saveImage(file multipart.File, imagePath string) error {
-	// Dosyayı aç
-	dst, err := os.Create(imagePath)
-	if err != nil {
-		return fmt.Errorf("dosya oluşturulamadı: %w", err)
-	}
-	defer dst.Close()
-
-	// Gelen veriyi dosyaya kopyala
-	_, err = io.Copy(dst, file)
-	if err != nil {
-		return fmt.Errorf("dosya kopyalanamadı: %w", err)
+	if err := render.HTML(w, http.StatusOK, "createPost", render.NewPage(w, r)); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 	}
-
-	return nil
 }
 
 // Bir gönderiye yeni bir yorum eklemek için kullanılan HTTP işleyicisidir.
@@ -182,9 +163,9 @@ func CreateCommentHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if r.Method == http.MethodPost {
-		err := r.ParseMultipartForm(32 << 20) // 32 MB maksimum upload boyutu
-		if err != nil {
-			utils.HandleErr(w, err, "Error parsing multipart form", http.StatusBadRequest)
+		r.Body = http.MaxBytesReader(w, r.Body, media.MaxRequestSize())
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			utils.WriteError(w, r, utils.BadRequest("The attached files are too large", err))
 			return
 		}
 
@@ -192,81 +173,79 @@ func CreateCommentHandler(w http.ResponseWriter, r *http.Request) {
 		content := r.FormValue("content")
 
 		if content == "" {
-			utils.HandleErr(w, nil, "Content is required", http.StatusBadRequest)
+			utils.HandleErr(w, r, nil, "Content is required", http.StatusBadRequest)
 			return
 		}
 
 		postID, err := strconv.Atoi(postIDStr)
 		if err != nil {
-			utils.HandleErr(w, err, "Invalid post ID", http.StatusBadRequest)
+			utils.HandleErr(w, r, err, "Invalid post ID", http.StatusBadRequest)
 			return
 		}
 
-		// Yorum fotoğrafını işle
-		var commentImagePath string
-		newFilename := ""
-
-		file, handler, err := r.FormFile("commentImage")
-		if err == nil { // Dosya varsa işle
-			defer file.Close()
-
-			// Dosya boyutunu kontrol et
-			if handler.Size > maxUploadSize {
-				utils.HandleErr(w, fmt.Errorf("file size exceeds limit"), "File size exceeds limit (20MB)", http.StatusBadRequest)
-				return
-			}
-
-			// Dosya uzantısını kontrol et
-			ext := filepath.Ext(handler.Filename)
-			allowedExtensions := map[string]bool{
-				".jpg":  true,
-				".jpeg": true,
-				".png":  true,
-				".gif":  true,
-			}
-			if !allowedExtensions[ext] {
-				utils.HandleErr(w, fmt.Errorf("unsupported image format: %s", ext), "Unsupported image format", http.StatusBadRequest)
-				return
-			}
-
-			// Benzersiz dosya adı oluştur ve newFilename'e ata
-			imageUUID := uuid.New().String()
-			newFilename = imageUUID + ext
-
-			// uploads dizininin var olup olmadığını kontrol et, yoksa oluştur
-			uploadsDir := "./uploads"
-			if _, err := os.Stat(uploadsDir); os.IsNotExist(err) {
-				os.Mkdir(uploadsDir, 0o755) // Klasörü oluştur
-			}
-
-			// Kaydedilecek dosyanın tam yolunu oluştur
-			commentImagePath = filepath.Join(uploadsDir, newFilename)
-
-			// Fotoğrafı kaydet
-			if err := saveImage(file, commentImagePath); err != nil {
-				utils.HandleErr(w, err, "Error saving image", http.StatusInternalServerError)
-				return
-			}
-		} else if err != http.ErrMissingFile { // Dosya yoksa hata ver
-			utils.HandleErr(w, err, "Error getting image", http.StatusBadRequest)
+		// Yorum eklerini işle (varsa)
+		attachments, err := media.FromFormFiles(r, "attachments", "captions", "comment", session.UserID, maxCommentAttachments)
+		if err != nil {
+			utils.WriteError(w, r, err)
 			return
 		}
+		links, imageFilename := attachmentLinks(attachments)
 
-		// Veritabanına kaydet (commentImagePath ile birlikte)
-		_, err = datahandlers.DB.Exec("INSERT INTO comments (post_id, user_id, content, created_at, image_path) VALUES (?, ?, ?, ?, ?)",
-			postID, session.UserID, content, time.Now(), newFilename)
+		// Yorumu ve eklerini tek işlemde kaydet
+		commentID, err := datahandlers.CreateComment(datahandlers.NewComment{
+			PostID: int64(postID), UserID: session.UserID, Content: content, ImagePath: imageFilename, Attachments: links,
+		})
+		if err == sql.ErrNoRows {
+			utils.WriteError(w, r, utils.NotFound("Post not found"))
+			return
+		}
 		if err != nil {
-			utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+			utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 			return
 		}
+		mentioned, err := datahandlers.RecordMentions(session.UserID, int64(postID), commentID, content)
+		if err == nil {
+			err = datahandlers.AutoFollowPost(session.UserID, int64(postID))
+		}
+		if err == nil {
+			err = notifications.CommentCreated(session.UserID, int64(postID), commentID, mentioned)
+		}
+		if err != nil {
+			log.Printf("comment %d: %v", commentID, err)
+		}
+		if err := datahandlers.RefreshPostScores(int64(postID)); err != nil {
+			log.Printf("post %d scores: %v", postID, err)
+		}
+		metrics.CommentCreated()
+		badges.Trigger(session.UserID, badges.CommentCreated)
+		events.Publish(events.PostTopic(int64(postID)), "comment", map[string]int64{"id": commentID, "post_id": int64(postID)})
 
+		flash.AddSuccess(w, r, "Comment added.")
 		http.Redirect(w, r, fmt.Sprintf("/viewPost?id=%d", postID), http.StatusSeeOther)
 		return
 	}
 }
 
+// Yüklenen ekleri bağlanacak sırayla döndürür. İlk görsel, akıştaki küçük resim için
+// image_path sütununda da saklanır.
+func attachmentLinks(attachments []*media.Attachment) ([]datahandlers.AttachmentLink, string) {
+	links := make([]datahandlers.AttachmentLink, 0, len(attachments))
+	cover := ""
+	for _, a := range attachments {
+		links = append(links, datahandlers.AttachmentLink{ID: a.ID, Caption: a.Caption})
+		if cover == "" && a.IsImage() {
+			cover = a.Filename
+		}
+	}
+	return links, cover
+}
+
 // Belirli bir yorumu silmek için kullanılan HTTP işleyicisidir.
 func DeletePostHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
 	session, err := datahandlers.GetSession(r)
 	if err != nil || session == nil {
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
@@ -275,28 +254,73 @@ func DeletePostHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Admin olup olmadığını kontrol et
 	isAdmin, err := homehandlers.CheckIfAdmin(int64(session.UserID)) // int dönüşümü
-	if err != nil || !isAdmin {
-		http.Redirect(w, r, "/", http.StatusForbidden)
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	if !isAdmin {
+		utils.WriteError(w, r, utils.Forbidden("Only admins can delete posts"))
 		return
 	}
 
 	postID := strings.TrimPrefix(r.URL.Path, "/posts/delete/")
 	if postID == "" {
-		http.Error(w, "Post ID is required", http.StatusBadRequest)
+		utils.HandleErr(w, r, nil, "Post ID is required", http.StatusBadRequest)
 		return
 	}
 
-	_, err = datahandlers.DB.Exec("DELETE FROM posts WHERE id = ?", postID)
+	// Gönderi silinmeden önce sahibi, başlığı ve bildirenleri bildirimler için alınır; hesabı
+	// silinmiş sahip 0 olur
+	var authorID int
+	var title string
+	err = datahandlers.DB.QueryRow("SELECT COALESCE((SELECT id FROM users WHERE id = posts.user_id), 0), title FROM posts WHERE id = ?",
+		postID).Scan(&authorID, &title)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, utils.NotFound("Post not found"))
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	id, _ := strconv.ParseInt(postID, 10, 64)
+	reporters, err := datahandlers.PostReporters(id)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	// Kabul edilmiş cevabın yazarı gönderiyle birlikte cevap puanını kaybeder
+	var answerAuthorID int
+	if q, err := datahandlers.PostQuestion(id); err == nil && q.AcceptedCommentID != 0 {
+		answerAuthorID, _ = datahandlers.VoteTargetAuthor(0, q.AcceptedCommentID)
+	}
+
+	if err := datahandlers.DeletePost(id); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	for _, userID := range []int{authorID, answerAuthorID} {
+		if userID == 0 {
+			continue
+		}
+		if err := reputation.ContentRemoved(userID); err != nil {
+			log.Printf("post %s removal reputation: %v", postID, err)
+		}
+	}
+	if err := notifications.PostRemoved(session.UserID, authorID, title, reporters); err != nil {
+		log.Printf("post %s removal notifications: %v", postID, err)
+	}
 
+	flash.AddSuccess(w, r, "Post deleted.")
 	http.Redirect(w, r, "/admin", http.StatusSeeOther)
 }
 
 // Belirli bir yorumu silmek için kullanılan HTTP işleyicisidir.
 func DeleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
 	session, err := datahandlers.GetSession(r)
 	if err != nil || session == nil {
 		http.Redirect(w, r, "/login", http.StatusSeeOther)
@@ -305,35 +329,45 @@ func DeleteCommentHandler(w http.ResponseWriter, r *http.Request) {
 
 	commentID := r.FormValue("comment_id")
 	if commentID == "" {
-		http.Error(w, "Comment ID is required", http.StatusBadRequest)
+		utils.HandleErr(w, r, nil, "Comment ID is required", http.StatusBadRequest)
 		return
 	}
 
 	var userID, postID int
 	err = datahandlers.DB.QueryRow("SELECT user_id, post_id FROM comments WHERE id = ?", commentID).Scan(&userID, &postID)
 	if err != nil {
-		utils.HandleErr(w, err, "Comment not found", http.StatusNotFound)
+		utils.HandleErr(w, r, err, "Comment not found", http.StatusNotFound)
 		return
 	}
 
 	var postOwnerID int
 	err = datahandlers.DB.QueryRow("SELECT user_id FROM posts WHERE id = ?", postID).Scan(&postOwnerID)
 	if err != nil {
-		utils.HandleErr(w, err, "Post not found", http.StatusNotFound)
+		utils.HandleErr(w, r, err, "Post not found", http.StatusNotFound)
 		return
 	}
 
 	if userID != session.UserID && postOwnerID != session.UserID {
-		http.Error(w, "You can only delete your own comments or comments on your posts", http.StatusForbidden)
+		utils.HandleErr(w, r, nil, "You can only delete your own comments or comments on your posts", http.StatusForbidden)
 		return
 	}
 
-	_, err = datahandlers.DB.Exec("UPDATE comments SET deleted = 1 WHERE id = ?", commentID)
-	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+	id, _ := strconv.ParseInt(commentID, 10, 64)
+	if err := datahandlers.DeleteComment(id); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if err := datahandlers.ClearAcceptedAnswer(id); err != nil {
+		log.Printf("comment %s accepted answer: %v", commentID, err)
+	}
+	if err := reputation.ContentRemoved(userID); err != nil {
+		log.Printf("comment %s removal reputation: %v", commentID, err)
+	}
+	if err := datahandlers.RefreshPostScores(int64(postID)); err != nil {
+		log.Printf("post %d scores: %v", postID, err)
+	}
 
+	flash.AddSuccess(w, r, "Comment deleted.")
 	http.Redirect(w, r, fmt.Sprintf("/viewPost?id=%d", postID), http.StatusSeeOther)
 }
 
@@ -344,27 +378,74 @@ func ReportPostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	postID := r.FormValue("post_id")
-	if postID == "" {
-		http.Error(w, "Post ID is required", http.StatusBadRequest)
+	postID := r.PathValue("id")
+	if _, err := strconv.Atoi(postID); err != nil {
+		utils.HandleErr(w, r, nil, "Invalid post ID", http.StatusBadRequest)
+		return
+	}
+
+	// GET isteğinde şikayet formu gösterilir
+	if r.Method != http.MethodPost {
+		data := struct {
+			render.Page
+			PostID string
+		}{
+			Page:   render.NewPage(w, r),
+			PostID: postID,
+		}
+		if err := render.HTML(w, http.StatusOK, "reportPost", data); err != nil {
+			utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
+		}
 		return
 	}
 
 	_, err = datahandlers.DB.Exec("INSERT INTO reports (post_id, user_id) VALUES (?, ?)", postID, session.UserID)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	flash.AddSuccess(w, r, "Report submitted. Thank you, a moderator will review it.")
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
+// Önizlenebilecek en büyük içerik
+const maxPreviewSize = 64 << 10
+
+// PreviewHandler, gönderi formundaki Markdown içeriği kaydedilecek HTML ile aynı şekilde
+// işleyip {"html": "..."} olarak döndürür. Yalnızca oturum açmış kullanıcılar kullanabilir.
+func PreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	session, err := datahandlers.GetSession(r)
+	if err != nil || session == nil {
+		utils.WriteError(w, r, utils.Unauthorized("You need to log in to preview posts"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPreviewSize)
+	if err := r.ParseForm(); err != nil {
+		utils.WriteError(w, r, utils.BadRequest("The content is too long to preview", err))
+		return
+	}
+
+	html, _ := datahandlers.RenderedContent(r.PostFormValue("content"))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"html": html})
+}
+
 // Gönderilere veya yorumlara oy vermek (beğenmek/beğenmemek) için kullanılır.
 func VoteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
 	session, err := datahandlers.GetSession(r)
 	if err != nil || session == nil {
-		w.WriteHeader(http.StatusUnauthorized)
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(map[string]string{"redirect": "/login"})
 		return
 	}
@@ -375,78 +456,94 @@ func VoteHandler(w http.ResponseWriter, r *http.Request) {
 
 	voteType, err := strconv.Atoi(voteTypeStr)
 	if err != nil || (voteType != 1 && voteType != -1) {
-		utils.HandleErr(w, err, "Invalid vote type", http.StatusBadRequest)
+		utils.HandleErr(w, r, err, "Invalid vote type", http.StatusBadRequest)
 		return
 	}
 
-	var existingVoteType sql.NullInt64
-	var query string
-
-	if postID != "" {
-		query = "SELECT vote_type FROM votes WHERE user_id = ? AND post_id = ?"
-		err = datahandlers.DB.QueryRow(query, session.UserID, postID).Scan(&existingVoteType)
-	} else if commentID != "" {
-		query = "SELECT vote_type FROM votes WHERE user_id = ? AND comment_id = ?"
-		err = datahandlers.DB.QueryRow(query, session.UserID, commentID).Scan(&existingVoteType)
+	// Gönderi ID'si verilmişse oy gönderiye aittir
+	votedPostID, _ := strconv.ParseInt(postID, 10, 64)
+	var votedCommentID int64
+	if postID == "" {
+		votedCommentID, _ = strconv.ParseInt(commentID, 10, 64)
 	}
-
-	if err != nil && err != sql.ErrNoRows {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+	if votedPostID == 0 && votedCommentID == 0 {
+		utils.WriteError(w, r, utils.BadRequest("Invalid post or comment ID", nil))
 		return
 	}
 
-	if existingVoteType.Valid {
-		if existingVoteType.Int64 == int64(voteType) {
-			if postID != "" {
-				query = "DELETE FROM votes WHERE user_id = ? AND post_id = ?"
-				_, err = datahandlers.DB.Exec(query, session.UserID, postID)
-			} else if commentID != "" {
-				query = "DELETE FROM votes WHERE user_id = ? AND comment_id = ?"
-				_, err = datahandlers.DB.Exec(query, session.UserID, commentID)
-			}
+	// Yeni bir beğenmeme puan yetkisi ister; mevcut beğenmemeyi geri almak istemez
+	if voteType == -1 {
+		var existing sql.NullInt64
+		if votedCommentID != 0 {
+			err = datahandlers.DB.QueryRow("SELECT vote_type FROM votes WHERE user_id = ? AND comment_id = ?", session.UserID, votedCommentID).Scan(&existing)
 		} else {
-			if postID != "" {
-				query = "UPDATE votes SET vote_type = ? WHERE user_id = ? AND post_id = ?"
-				_, err = datahandlers.DB.Exec(query, voteType, session.UserID, postID)
-			} else if commentID != "" {
-				query = "UPDATE votes SET vote_type = ? WHERE user_id = ? AND comment_id = ?"
-				_, err = datahandlers.DB.Exec(query, voteType, session.UserID, commentID)
-			}
+			err = datahandlers.DB.QueryRow("SELECT vote_type FROM votes WHERE user_id = ? AND post_id = ? AND comment_id IS NULL", session.UserID, votedPostID).Scan(&existing)
 		}
-	} else {
-		if postID != "" {
-			query = "INSERT INTO votes (user_id, post_id, vote_type) VALUES (?, ?, ?)"
-			_, err = datahandlers.DB.Exec(query, session.UserID, postID, voteType)
-		} else if commentID != "" {
-			query = "INSERT INTO votes (user_id, comment_id, vote_type) VALUES (?, ?, ?)"
-			_, err = datahandlers.DB.Exec(query, session.UserID, commentID, voteType)
+		if err != nil && err != sql.ErrNoRows {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		if existing.Int64 != -1 {
+			if err := reputation.Check(session.UserID, reputation.Downvote); err != nil {
+				utils.WriteError(w, r, err)
+				return
+			}
 		}
 	}
 
+	// Aynı oy tekrar verilirse geri alınır
+	oldVote, newVote, err := datahandlers.RecordVote(session.UserID, votedPostID, votedCommentID, voteType)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, utils.NotFound("Post or comment not found"))
+		return
+	}
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if oldVote == 0 {
+		if votedCommentID != 0 {
+			metrics.VoteCreated("comment", voteType)
+		} else {
+			metrics.VoteCreated("post", voteType)
+		}
+	}
 
-	// Oy sayısını yeniden hesapla ve JSON olarak dön
-	var likeCount, dislikeCount int
-	if postID != "" {
-		err = datahandlers.DB.QueryRow(`SELECT 
-			COALESCE(SUM(CASE WHEN vote_type = 1 THEN 1 ELSE 0 END), 0) AS like_count,
-			COALESCE(SUM(CASE WHEN vote_type = -1 THEN 1 ELSE 0 END), 0) AS dislike_count
-			FROM votes WHERE post_id = ?`, postID).Scan(&likeCount, &dislikeCount)
-	} else if commentID != "" {
-		err = datahandlers.DB.QueryRow(`SELECT 
-			COALESCE(SUM(CASE WHEN vote_type = 1 THEN 1 ELSE 0 END), 0) AS like_count,
-			COALESCE(SUM(CASE WHEN vote_type = -1 THEN 1 ELSE 0 END), 0) AS dislike_count
-			FROM votes WHERE comment_id = ?`, commentID).Scan(&likeCount, &dislikeCount)
+	if err := reputation.VoteChanged(session.UserID, votedPostID, votedCommentID, oldVote, newVote); err != nil {
+		log.Printf("vote reputation: %v", err)
+	}
+	badges.VoteChanged(session.UserID, votedPostID, votedCommentID)
+	if votedCommentID == 0 {
+		if err := datahandlers.RefreshPostScores(votedPostID); err != nil {
+			log.Printf("post %d scores: %v", votedPostID, err)
+		}
+	}
+
+	// Geri alınan oylar dışında içeriğin sahibine bildirim gönderilir
+	if newVote != 0 {
+		if err := notifications.Voted(session.UserID, votedPostID, votedCommentID, voteType); err != nil {
+			log.Printf("vote notification: %v", err)
+		}
 	}
 
+	// Güncel oy sayılarını JSON olarak dön
+	likeCount, dislikeCount, err := datahandlers.VoteCounts(votedPostID, votedCommentID)
 	if err != nil {
-		utils.HandleErr(w, err, "Internal server error", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
+	// Gönderi sayfasını açık tutan diğer kullanıcıların sayıları canlı güncellenir
+	if votedCommentID != 0 {
+		datahandlers.DB.QueryRow("SELECT post_id FROM comments WHERE id = ?", votedCommentID).Scan(&votedPostID)
+	}
+	if votedPostID != 0 {
+		events.Publish(events.PostTopic(votedPostID), "vote", map[string]int64{
+			"post_id": votedPostID, "comment_id": votedCommentID,
+			"like_count": int64(likeCount), "dislike_count": int64(dislikeCount),
+		})
+	}
+
 	response := map[string]int{"like_count": likeCount, "dislike_count": dislikeCount}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
@@ -454,41 +551,36 @@ func VoteHandler(w http.ResponseWriter, r *http.Request) {
 
 // Belirli bir gönderiyi ve altındaki yorumları görüntülemek için kullanılan HTTP işleyicisidir.
 func ViewPostHandler(w http.ResponseWriter, r *http.Request) {
-	session, _ := datahandlers.GetSession(r)
-
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		utils.HandleErr(w, r, nil, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	postIDStr := r.URL.Query().Get("id")
 	if postIDStr == "" {
-		http.Error(w, "Post ID required", http.StatusBadRequest)
+		utils.HandleErr(w, r, nil, "Post ID required", http.StatusBadRequest)
 		return
 	}
 
 	postID, err := strconv.Atoi(postIDStr)
 	if err != nil {
-		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		utils.HandleErr(w, r, err, "Invalid post ID", http.StatusBadRequest)
 		return
 	}
 
 	var post Post
-	var categoriesJSON string
-	err = datahandlers.DB.QueryRow(`SELECT posts.id, posts.user_id, posts.title, posts.content, posts.categories, posts.created_at, users.username, posts.image_path, 
-        COALESCE(SUM(CASE WHEN votes.vote_type = 1 THEN 1 ELSE 0 END), 0) AS like_count,
-        COALESCE(SUM(CASE WHEN votes.vote_type = -1 THEN 1 ELSE 0 END), 0) AS dislike_count
+	var categoriesJSON, contentHTML string
+	var contentVersion int
+	err = datahandlers.DB.QueryRow(`SELECT posts.id, posts.user_id, posts.title, posts.content, posts.content_html, posts.content_version, posts.categories, posts.created_at, users.username, posts.image_path, COALESCE(users.profile_picture_path, ''), users.reputation,
+        posts.is_question, COALESCE(posts.accepted_comment_id, 0), posts.like_count, posts.dislike_count
         FROM posts
         JOIN users ON posts.user_id = users.id
-        LEFT JOIN votes ON votes.post_id = posts.id
-        WHERE posts.id = ? AND posts.deleted = 0
-        GROUP BY posts.id`, postID).Scan(&post.ID, &post.UserID, &post.Title, &post.Content, &categoriesJSON, &post.CreatedAt, &post.Username, &post.ImagePath, &post.LikeCount, &post.DislikeCount)
+        WHERE posts.id = ? AND posts.deleted = 0`, postID).Scan(&post.ID, &post.UserID, &post.Title, &post.Content, &contentHTML, &contentVersion, &categoriesJSON, &post.CreatedAt, &post.Username, &post.ImagePath, &post.AvatarPath, &post.AuthorReputation, &post.IsQuestion, &post.AcceptedCommentID, &post.LikeCount, &post.DislikeCount)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			http.Error(w, "Post not found", http.StatusNotFound)
+			utils.HandleErr(w, r, nil, "Post not found", http.StatusNotFound)
 		} else {
-			log.Println("Error querying post:", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
 		}
 		return
 	}
@@ -496,67 +588,170 @@ func ViewPostHandler(w http.ResponseWriter, r *http.Request) {
 	var categories []string
 	err = json.Unmarshal([]byte(categoriesJSON), &categories)
 	if err != nil {
-		utils.HandleErr(w, err, "Error parsing categories", http.StatusInternalServerError)
+		utils.HandleErr(w, r, err, "Error parsing categories", http.StatusInternalServerError)
 		return
 	}
 
 	post.CreatedAtFormatted = post.CreatedAt.Format("2006-01-02 15:04")
 	post.Categories = categories
+	post.ContentHTML = datahandlers.PostHTML(post.ID, post.Content, contentHTML, contentVersion)
+	if post.Attachments, err = datahandlers.PostAttachments(post.ID); err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	comments, err := postComments(post.ID, post.AcceptedCommentID, 0)
+	if err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	page := render.NewPage(w, r)
+	following := false
+	if page.CurrentUser != nil {
+		if following, err = datahandlers.IsFollowingPost(page.CurrentUser.ID, int64(post.ID)); err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		saved, err := datahandlers.PostBookmarks(page.CurrentUser.ID, int64(post.ID))
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		post.BookmarkID = saved[0]
+		for i := range comments {
+			comments[i].BookmarkID = saved[int64(comments[i].ID)]
+		}
+	}
+
+	// Soruyu işaretleyip cevap kabul edebilenler: gönderinin yazarı ve moderatörler
+	canManage := page.CurrentUser != nil && (page.CurrentUser.ID == post.UserID || page.CurrentUser.IsModerator())
+
+	data := struct {
+		render.Page
+		Post      Post
+		Comments  []Comment
+		Following bool // Oturumdaki kullanıcı gönderiyi takip ediyor mu
+		CanManage bool
+	}{
+		Page:      page,
+		Post:      post,
+		Comments:  comments,
+		Following: following,
+		CanManage: canManage,
+	}
+
+	if err := render.HTML(w, http.StatusOK, "viewPost", data); err != nil {
+		utils.HandleErr(w, r, err, "Internal server error", http.StatusInternalServerError)
+	}
+}
 
-	rows, err := datahandlers.DB.Query(`
-        SELECT c.id, c.post_id, c.user_id, c.content, c.created_at, u.username, c.image_path, 
-               COALESCE(SUM(CASE WHEN v.vote_type = 1 THEN 1 ELSE 0 END), 0) AS like_count,
-               COALESCE(SUM(CASE WHEN v.vote_type = -1 THEN 1 ELSE 0 END), 0) AS dislike_count
+// Gönderinin silinmemiş yorumlarını kabul edilmiş cevap önce, sonra yeniden eskiye doğru
+// döndürür; commentID verilmişse yalnızca o yorum döner.
+func postComments(postID int, acceptedCommentID int64, commentID int) ([]Comment, error) {
+	commentAttachments, err := datahandlers.CommentAttachments(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT c.id, c.post_id, c.user_id, c.content, c.content_html, c.content_version, c.created_at, u.username, c.image_path, COALESCE(u.profile_picture_path, ''), u.reputation,
+               c.like_count, c.dislike_count
         FROM comments c
         JOIN users u ON c.user_id = u.id
-        LEFT JOIN votes v ON v.comment_id = c.id
-        WHERE c.post_id = ? AND c.deleted = 0
-        GROUP BY c.id, c.post_id, c.user_id, c.content, c.created_at, u.username, c.image_path 
-        ORDER BY c.created_at DESC`, postID)
+        WHERE c.post_id = ? AND c.deleted = 0`
+	args := []interface{}{postID}
+	if commentID != 0 {
+		query += " AND c.id = ?"
+		args = append(args, commentID)
+	}
+	rows, err := datahandlers.DB.Query(query+" ORDER BY c.id = ? DESC, c.created_at DESC", append(args, acceptedCommentID)...)
 	if err != nil {
-		log.Println("Error querying comments:", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
 	var comments []Comment
 	for rows.Next() {
 		var comment Comment
-		err := rows.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.Content, &comment.CreatedAt, &comment.Username, &comment.ImagePath, &comment.LikeCount, &comment.DislikeCount)
+		err := rows.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.Content, &comment.cachedHTML, &comment.cachedVersion, &comment.CreatedAt, &comment.Username, &comment.ImagePath, &comment.AvatarPath, &comment.AuthorReputation, &comment.LikeCount, &comment.DislikeCount)
 		if err != nil {
-			log.Println("Error scanning comment:", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-			return
+			return nil, err
 		}
 		comment.CreatedAtFormatted = comment.CreatedAt.Format("2006-01-02 15:04")
+		comment.Attachments = commentAttachments[comment.ID]
+		comment.Accepted = int64(comment.ID) == acceptedCommentID
 		comments = append(comments, comment)
 	}
 	if err := rows.Err(); err != nil {
-		log.Println("Rows error:", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+		return nil, err
+	}
+	// Eski önbellekler güncellenirken okuma kilidi tutulmasın diye sorgu önce kapatılır
+	rows.Close()
+	for i := range comments {
+		c := &comments[i]
+		c.ContentHTML = datahandlers.CommentHTML(c.ID, c.Content, c.cachedHTML, c.cachedVersion)
 	}
+	return comments, nil
+}
 
-	data := struct {
-		Post     Post
-		Comments []Comment
-		LoggedIn bool
-	}{
-		Post:     post,
-		Comments: comments,
-		LoggedIn: session != nil,
+// CommentHandler, /comments/{id} adresinde tek bir yorumu, gönderi sayfasındaki haliyle ve
+// okuyucunun yetkilerine göre (ör. silme düğmesi) sayfa düzeni olmadan döndürür. Canlı
+// güncellemelerde yeni yorumlar bu parçayla sayfaya eklenir.
+func CommentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return
+	}
+	commentID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequest("Invalid comment ID", err))
+		return
 	}
 
-	tmpl, err := template.ParseFiles("templates/viewPost.html")
+	var postID, postOwnerID int
+	var isQuestion bool
+	var acceptedCommentID int64
+	err = datahandlers.DB.QueryRow(`SELECT p.id, p.user_id, p.is_question, COALESCE(p.accepted_comment_id, 0)
+		FROM comments c JOIN posts p ON p.id = c.post_id
+		WHERE c.id = ? AND c.deleted = 0 AND p.deleted = 0`, commentID).Scan(&postID, &postOwnerID, &isQuestion, &acceptedCommentID)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, utils.NotFound("Comment not found"))
+		return
+	}
 	if err != nil {
-		log.Println("Error parsing template:", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		utils.WriteError(w, r, utils.Internal(err))
 		return
 	}
-	err = tmpl.Execute(w, data)
+	comments, err := postComments(postID, acceptedCommentID, commentID)
 	if err != nil {
-		log.Println("Error executing template:", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	if len(comments) == 0 {
+		utils.WriteError(w, r, utils.NotFound("Comment not found"))
+		return
+	}
+	comment := comments[0]
+
+	page := render.NewPartialPage(w, r)
+	canManage := false
+	if page.CurrentUser != nil {
+		saved, err := datahandlers.PostBookmarks(page.CurrentUser.ID, int64(postID))
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+		comment.BookmarkID = saved[int64(comment.ID)]
+		canManage = page.CurrentUser.ID == postOwnerID || page.CurrentUser.IsModerator()
+	}
+
+	data := map[string]interface{}{
+		"Comment":     comment,
+		"Page":        page,
+		"PostOwnerID": postOwnerID,
+		"CanAccept":   canManage && isQuestion,
+	}
+	if err := render.Partial(w, http.StatusOK, "comment", data); err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
 	}
 }
@@ -0,0 +1,129 @@
+package posthandlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"form-project/badges"
+	"form-project/datahandlers"
+	"form-project/flash"
+	"form-project/notifications"
+	"form-project/reputation"
+	"form-project/utils"
+)
+
+// Soru/cevap işlemleri için gönderiyi ve işlemi yapabilecek kullanıcıyı (gönderinin yazarı
+// veya moderatör) doğrular. Hata yazıldıysa ok false döner.
+func questionRequest(w http.ResponseWriter, r *http.Request) (user *datahandlers.SessionUser, q datahandlers.Question, ok bool) {
+	if r.Method != http.MethodPost {
+		utils.WriteError(w, r, utils.MethodNotAllowed())
+		return nil, q, false
+	}
+	user, err := datahandlers.GetSessionUser(r)
+	if err != nil || user == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return nil, q, false
+	}
+	postID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		utils.WriteError(w, r, utils.BadRequest("Invalid post ID", err))
+		return nil, q, false
+	}
+	q, err = datahandlers.PostQuestion(postID)
+	if err == sql.ErrNoRows {
+		utils.WriteError(w, r, utils.NotFound("Post not found"))
+		return nil, q, false
+	}
+	if err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return nil, q, false
+	}
+	if q.AuthorID != user.ID && !user.IsModerator() {
+		utils.WriteError(w, r, utils.Forbidden("Only the author of the post or a moderator can do this"))
+		return nil, q, false
+	}
+	return user, q, true
+}
+
+// MarkQuestionHandler, /posts/question/{id} adresinde gönderiyi soru olarak işaretler ya da
+// ("action=unmark") işareti kaldırır. İşaret kaldırılınca kabul edilmiş cevap da kaldırılır.
+func MarkQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	_, q, ok := questionRequest(w, r)
+	if !ok {
+		return
+	}
+
+	question := r.FormValue("action") != "unmark"
+	if err := datahandlers.SetQuestion(q.PostID, question); err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	if question {
+		flash.AddSuccess(w, r, "The post is now marked as a question.")
+	} else {
+		if err := reputation.AnswerAccepted(q.AuthorID, q.AcceptedCommentID, 0); err != nil {
+			log.Printf("post %d answer reputation: %v", q.PostID, err)
+		}
+		flash.AddSuccess(w, r, "The post is no longer marked as a question.")
+	}
+	http.Redirect(w, r, fmt.Sprintf("/viewPost?id=%d", q.PostID), http.StatusSeeOther)
+}
+
+// AcceptAnswerHandler, /posts/accept/{id} adresinde sorudaki bir yorumu ("comment_id") kabul
+// edilmiş cevap olarak işaretler ya da ("action=unaccept") işareti kaldırır. Bir sorunun en
+// fazla bir kabul edilmiş cevabı olur; yeni cevap öncekinin yerini alır.
+func AcceptAnswerHandler(w http.ResponseWriter, r *http.Request) {
+	user, q, ok := questionRequest(w, r)
+	if !ok {
+		return
+	}
+	if !q.IsQuestion {
+		utils.WriteError(w, r, utils.BadRequest("Only questions can have an accepted answer", nil))
+		return
+	}
+
+	var commentID int64
+	answerAuthorID := 0
+	if r.FormValue("action") != "unaccept" {
+		var err error
+		commentID, err = strconv.ParseInt(r.FormValue("comment_id"), 10, 64)
+		if err != nil {
+			utils.WriteError(w, r, utils.BadRequest("Invalid comment ID", err))
+			return
+		}
+		answerAuthorID, err = datahandlers.AnswerAuthor(q.PostID, commentID)
+		if err == sql.ErrNoRows {
+			utils.WriteError(w, r, utils.NotFound("Comment not found"))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(err))
+			return
+		}
+	}
+
+	if err := datahandlers.SetAcceptedAnswer(q.PostID, commentID); err != nil {
+		utils.WriteError(w, r, utils.Internal(err))
+		return
+	}
+	if err := reputation.AnswerAccepted(q.AuthorID, q.AcceptedCommentID, commentID); err != nil {
+		log.Printf("post %d answer reputation: %v", q.PostID, err)
+	}
+	if commentID == 0 {
+		flash.AddSuccess(w, r, "The accepted answer was removed.")
+		http.Redirect(w, r, fmt.Sprintf("/viewPost?id=%d", q.PostID), http.StatusSeeOther)
+		return
+	}
+
+	if commentID != q.AcceptedCommentID && answerAuthorID != q.AuthorID {
+		if err := notifications.AnswerAccepted(user.ID, answerAuthorID, q.PostID, commentID); err != nil {
+			log.Printf("post %d answer notification: %v", q.PostID, err)
+		}
+		badges.Trigger(answerAuthorID, badges.AnswerAccepted)
+	}
+	flash.AddSuccess(w, r, "Answer accepted.")
+	http.Redirect(w, r, fmt.Sprintf("/viewPost?id=%d#comment-%d", q.PostID, commentID), http.StatusSeeOther)
+}
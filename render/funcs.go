@@ -0,0 +1,76 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+	"time"
+
+	"form-project/markdown"
+	"form-project/security"
+)
+
+// Tüm şablonlarda kullanılabilen yardımcı fonksiyonlar
+var funcs = template.FuncMap{
+	"timeAgo":   timeAgo,
+	"pluralize": pluralize,
+	"markdown":  markdown.Render,
+	"csrfField": csrfField,
+	"dict":      dict,
+	"userURL":   userURL,
+}
+
+// Tarihi "3 minutes ago" gibi göreli bir ifadeye çevirir.
+func timeAgo(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return pluralize(int(d.Minutes()), "minute", "minutes") + " ago"
+	case d < 24*time.Hour:
+		return pluralize(int(d.Hours()), "hour", "hours") + " ago"
+	case d < 30*24*time.Hour:
+		return pluralize(int(d.Hours()/24), "day", "days") + " ago"
+	case d < 365*24*time.Hour:
+		return pluralize(int(d.Hours()/(24*30)), "month", "months") + " ago"
+	default:
+		return pluralize(int(d.Hours()/(24*365)), "year", "years") + " ago"
+	}
+}
+
+// Sayıya göre tekil veya çoğul ifadeyi döndürür: pluralize 1 "Like" "Likes" -> "1 Like"
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// Formlara eklenecek gizli CSRF alanını üretir: {{csrfField .CSRFToken}}
+func csrfField(token string) template.HTML {
+	return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`,
+		security.CSRFFieldName, html.EscapeString(token)))
+}
+
+// Parçalara (partials) birden fazla değer geçirmek için: {{template "post_card" dict "Post" . "Page" $}}
+func dict(values ...interface{}) (map[string]interface{}, error) {
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	m := make(map[string]interface{}, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		key, ok := values[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", values[i])
+		}
+		m[key] = values[i+1]
+	}
+	return m, nil
+}
+
+// Kullanıcının herkese açık profil sayfasının adresi: {{userURL .Post.Username}}
+func userURL(username string) string {
+	return "/u/" + url.PathEscape(username)
+}
@@ -0,0 +1,66 @@
+package render
+
+import (
+	"net/http"
+
+	"form-project/datahandlers"
+	"form-project/flash"
+	"form-project/security"
+)
+
+// Page, tüm sayfaların ortak düzende kullandığı bağlamdır. İşleyiciler kendi
+// şablon verilerine Page'i gömer (embed); böylece şablonlar .LoggedIn, .IsAdmin,
+// .CurrentUser, .Flashes gibi alanlara doğrudan erişir.
+type Page struct {
+	CurrentUser *datahandlers.SessionUser // Oturum yoksa nil
+	LoggedIn    bool
+	IsAdmin     bool
+	IsModerator bool
+	CSRFToken   string
+	Flashes     []flash.Message // Önceki istekte eklenen, bu sayfada bir kez gösterilecek mesajlar
+	// Üst çubuktaki bildirim zilinde gösterilen okunmamış bildirim sayısı
+	UnreadNotifications int
+}
+
+// PageData, render.HTML'e verilebilen şablon verisidir (Page'i gömen her yapı bunu sağlar).
+type PageData interface {
+	PageContext() Page
+}
+
+func (p Page) PageContext() Page {
+	return p
+}
+
+// İstek için ortak sayfa bağlamını oluşturur.
+func NewPage(w http.ResponseWriter, r *http.Request) Page {
+	// Geçersiz veya süresi dolmuş oturumlar ziyaretçi olarak kabul edilir
+	user, _ := datahandlers.GetSessionUser(r)
+	unread := 0
+	if user != nil {
+		unread, _ = datahandlers.UnreadNotificationCount(user.ID)
+	}
+
+	return Page{
+		CurrentUser: user,
+		LoggedIn:    user != nil,
+		IsAdmin:     user.IsAdmin(),
+		IsModerator: user.IsModerator(),
+		CSRFToken:   security.CSRFToken(w, r),
+		Flashes:     flash.Pop(w, r),
+
+		UnreadNotifications: unread,
+	}
+}
+
+// NewPartialPage, sayfaya sonradan eklenen parçalar için oturum bilgilerini ve CSRF belirtecini
+// içeren bağlamı oluşturur. Flash mesajları tüketilmez; okunmamış bildirim sayısı hesaplanmaz.
+func NewPartialPage(w http.ResponseWriter, r *http.Request) Page {
+	user, _ := datahandlers.GetSessionUser(r)
+	return Page{
+		CurrentUser: user,
+		LoggedIn:    user != nil,
+		IsAdmin:     user.IsAdmin(),
+		IsModerator: user.IsModerator(),
+		CSRFToken:   security.CSRFToken(w, r),
+	}
+}
@@ -0,0 +1,188 @@
+package render // HTML şablonlarını başlangıçta bir kez ayrıştırıp önbellekte tutan ve sayfaları işleyen paket
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Her sayfa, ortak düzen (layouts) ve parçalarla (partials) birlikte ayrıştırılır
+// ve "base" şablonu üzerinden işlenir.
+const baseTemplate = "base"
+
+var (
+	mu        sync.RWMutex
+	dir       = "templates"
+	devMode   bool
+	templates map[string]*template.Template
+	partials  *template.Template // Düzen olmadan işlenen parçalar (Partial)
+)
+
+// Load, dizindeki tüm sayfaları ayrıştırıp önbelleğe alır. dev true ise şablonlar
+// her istekte diskten yeniden okunur, böylece sunucuyu yeniden başlatmadan düzenlenebilir.
+func Load(templateDir string, dev bool) error {
+	parsed, err := parseAll(templateDir)
+	if err != nil {
+		return err
+	}
+	parts, err := parsePartials(templateDir)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	dir = templateDir
+	devMode = dev
+	templates = parsed
+	partials = parts
+	return nil
+}
+
+// AddFuncs, şablon yardımcılarını başka paketlerden ekler (ör. media); Load'dan önce çağrılmalıdır.
+func AddFuncs(extra template.FuncMap) {
+	mu.Lock()
+	defer mu.Unlock()
+	for name, fn := range extra {
+		funcs[name] = fn
+	}
+}
+
+// Check, şablonların ayrıştırılmış ve kullanılabilir olduğunu doğrular (hazır olma kontrolü için).
+func Check() error {
+	mu.RLock()
+	defer mu.RUnlock()
+	if devMode {
+		_, err := parseAll(dir)
+		return err
+	}
+	if len(templates) == 0 {
+		return fmt.Errorf("templates are not loaded")
+	}
+	return nil
+}
+
+// HTML, sayfayı önce bir tampona işler; hata yoksa durum kodu ile birlikte yanıta yazar.
+// Böylece şablon hatası durumunda yarım kalmış bir sayfa gönderilmez.
+func HTML(w http.ResponseWriter, status int, name string, data PageData) error {
+	tmpl, err := lookup(name)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, baseTemplate, data); err != nil {
+		return fmt.Errorf("error executing template %s: %w", name, err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// Partial, tek bir parçayı (ör. "comment") sayfa düzeni olmadan işler; canlı güncellemelerle
+// sayfaya eklenen HTML parçaları içindir.
+func Partial(w http.ResponseWriter, status int, name string, data interface{}) error {
+	mu.RLock()
+	dev, templateDir, tmpl := devMode, dir, partials
+	mu.RUnlock()
+	if dev {
+		var err error
+		if tmpl, err = parsePartials(templateDir); err != nil {
+			return err
+		}
+	}
+	if tmpl == nil {
+		return fmt.Errorf("partials are not loaded")
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return fmt.Errorf("error executing partial %s: %w", name, err)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func lookup(name string) (*template.Template, error) {
+	mu.RLock()
+	dev, templateDir := devMode, dir
+	tmpl, ok := templates[name]
+	mu.RUnlock()
+
+	if dev {
+		return parsePage(templateDir, filepath.Join(templateDir, name+".html"))
+	}
+	if !ok {
+		return nil, fmt.Errorf("template %s not found", name)
+	}
+	return tmpl, nil
+}
+
+func parseAll(templateDir string) (map[string]*template.Template, error) {
+	pages, err := filepath.Glob(filepath.Join(templateDir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", templateDir)
+	}
+
+	parsed := make(map[string]*template.Template, len(pages))
+	for _, page := range pages {
+		tmpl, err := parsePage(templateDir, page)
+		if err != nil {
+			return nil, err
+		}
+		parsed[strings.TrimSuffix(filepath.Base(page), ".html")] = tmpl
+	}
+	return parsed, nil
+}
+
+// Parça şablonlarını tek başına ayrıştırır.
+func parsePartials(templateDir string) (*template.Template, error) {
+	files, err := filepath.Glob(filepath.Join(templateDir, "partials/*.html"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no partials found in %s", templateDir)
+	}
+	tmpl, err := template.New("partials").Funcs(funcs).ParseFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing partials: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Bir sayfayı düzen ve parça şablonlarıyla birlikte ayrıştırır.
+func parsePage(templateDir, page string) (*template.Template, error) {
+	tmpl := template.New(filepath.Base(page)).Funcs(funcs)
+
+	for _, pattern := range []string{"layouts/*.html", "partials/*.html"} {
+		files, err := filepath.Glob(filepath.Join(templateDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+		if tmpl, err = tmpl.ParseFiles(files...); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", pattern, err)
+		}
+	}
+
+	tmpl, err := tmpl.ParseFiles(page)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", page, err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,153 @@
+package reputation // Oylardan hesaplanan kullanıcı puanı ve puanla açılan yetkiler
+
+import (
+	"fmt"
+
+	"form-project/config"
+	"form-project/datahandlers"
+	"form-project/utils"
+)
+
+// Puanla açılan yetkiler; eşikler config.json'daki reputation.privileges ile ayarlanır.
+const (
+	Downvote   = "downvote"
+	PostImages = "post_images"
+)
+
+// Yetkilerin kullanıcıya gösterilen açıklamaları, gösterim sırasıyla
+var privileges = []struct{ name, label string }{
+	{PostImages, "post images"},
+	{Downvote, "downvote"},
+}
+
+var (
+	weights   datahandlers.ReputationWeights
+	required  map[string]int
+	moderated = map[string]bool{"admin": true, "moderator": true}
+)
+
+func init() {
+	SetConfig(config.Reputation{
+		Weights:    config.DefaultReputationWeights,
+		Privileges: config.DefaultPrivileges,
+	})
+}
+
+// SetConfig, oy ağırlıklarını ve yetki eşiklerini yapılandırmadan ayarlar.
+func SetConfig(cfg config.Reputation) {
+	weights = datahandlers.ReputationWeights{
+		PostLike:       int(cfg.Weights["post_like"]),
+		PostDislike:    int(cfg.Weights["post_dislike"]),
+		CommentLike:    int(cfg.Weights["comment_like"]),
+		CommentDislike: int(cfg.Weights["comment_dislike"]),
+		AcceptedAnswer: int(cfg.Weights["accepted_answer"]),
+	}
+	required = make(map[string]int, len(cfg.Privileges))
+	for name, min := range cfg.Privileges {
+		required[name] = int(min)
+	}
+}
+
+// Sync, puanları yapılandırmadaki ağırlıklarla hesaplanmamışsa yeniden hesaplar.
+func Sync() (bool, error) {
+	return datahandlers.SyncReputation(weights)
+}
+
+// VoteChanged, bir oy verildiğinde, değiştirildiğinde veya geri alındığında içeriğin
+// yazarının puanını farkı kadar günceller. Oy yoksa oldVote/newVote 0'dır.
+func VoteChanged(voterID int, postID, commentID int64, oldVote, newVote int) error {
+	authorID, err := datahandlers.VoteTargetAuthor(postID, commentID)
+	if err != nil || authorID == 0 || authorID == voterID {
+		return err
+	}
+	comment := commentID != 0
+	return datahandlers.AddReputation(authorID, weights.Weight(comment, newVote)-weights.Weight(comment, oldVote))
+}
+
+// AnswerAccepted, sorunun kabul edilmiş cevabı değiştiğinde eski cevabın yazarından puanı
+// geri alır ve yeni cevabın yazarına verir. Cevap yoksa commentID 0'dır; sorunun yazarının
+// kendi cevapları puan kazandırmaz.
+func AnswerAccepted(questionAuthorID int, oldCommentID, newCommentID int64) error {
+	if oldCommentID == newCommentID {
+		return nil
+	}
+	if err := answerBonus(questionAuthorID, oldCommentID, -weights.AcceptedAnswer); err != nil {
+		return err
+	}
+	return answerBonus(questionAuthorID, newCommentID, weights.AcceptedAnswer)
+}
+
+func answerBonus(questionAuthorID int, commentID int64, delta int) error {
+	if commentID == 0 {
+		return nil
+	}
+	authorID, err := datahandlers.VoteTargetAuthor(0, commentID)
+	if err != nil || authorID == 0 || authorID == questionAuthorID {
+		return err
+	}
+	return datahandlers.AddReputation(authorID, delta)
+}
+
+// ContentRemoved, yazarın gönderisi veya yorumu silindiğinde puanını yeniden hesaplar.
+func ContentRemoved(authorID int) error {
+	return datahandlers.RecalculateReputation(weights, authorID)
+}
+
+// Required, yetki için gereken en düşük puandır.
+func Required(privilege string) int {
+	return required[privilege]
+}
+
+// Allowed, kullanıcının yetkiyi açıp açmadığını döndürür. Admin ve moderatörler puandan bağımsızdır.
+func Allowed(userID int, privilege string) (bool, error) {
+	score, role, err := datahandlers.ReputationAndRole(userID)
+	if err != nil {
+		return false, fmt.Errorf("error reading reputation of user %d: %v", userID, err)
+	}
+	return moderated[role] || score >= required[privilege], nil
+}
+
+// Check, kullanıcı yetkiye sahip değilse 403 döndürür.
+func Check(userID int, privilege string) error {
+	ok, err := Allowed(userID, privilege)
+	if err != nil {
+		return utils.Internal(err)
+	}
+	if !ok {
+		return utils.Forbidden(fmt.Sprintf("You need at least %d reputation to %s", required[privilege], label(privilege)))
+	}
+	return nil
+}
+
+// Privilege, profil sayfasında gösterilen bir yetkinin durumudur.
+type Privilege struct {
+	Label    string
+	Required int
+	Unlocked bool
+}
+
+// Privileges, kullanıcının puanına göre tüm yetkilerin durumunu döndürür.
+func Privileges(userID int) ([]Privilege, error) {
+	score, role, err := datahandlers.ReputationAndRole(userID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Privilege, 0, len(privileges))
+	for _, p := range privileges {
+		list = append(list, Privilege{
+			Label:    p.label,
+			Required: required[p.name],
+			Unlocked: moderated[role] || score >= required[p.name],
+		})
+	}
+	return list, nil
+}
+
+func label(privilege string) string {
+	for _, p := range privileges {
+		if p.name == privilege {
+			return p.label
+		}
+	}
+	return privilege
+}
@@ -0,0 +1,69 @@
+package security // CSRF koruması gibi güvenlik yardımcılarını içeren paket
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+)
+
+const (
+	csrfCookieName = "csrf_token"
+	CSRFFieldName  = "csrf_token"   // Formlardaki gizli alanın adı
+	CSRFHeaderName = "X-CSRF-Token" // fetch/XHR istekleri için başlık
+)
+
+// İstek için CSRF belirtecini döndürür; çerezde yoksa yenisini oluşturup çereze yazar.
+// Belirteç "double submit" yöntemiyle doğrulanır: formdaki değer çerezdeki değerle eşleşmelidir.
+func CSRFToken(w http.ResponseWriter, r *http.Request) string {
+	if cookie, err := r.Cookie(csrfCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	token := randomToken()
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	// Aynı istek içinde tekrar sorulursa aynı belirteç dönsün
+	r.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+	return token
+}
+
+// Durum değiştiren isteklerin (POST, PUT, PATCH, DELETE) geçerli bir CSRF belirteci taşıyıp taşımadığını kontrol eder.
+func ValidCSRF(r *http.Request) bool {
+	if SafeMethod(r.Method) {
+		return true
+	}
+
+	cookie, err := r.Cookie(csrfCookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	token := r.Header.Get(CSRFHeaderName)
+	if token == "" {
+		token = r.FormValue(CSRFFieldName)
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(cookie.Value)) == 1
+}
+
+// SafeMethod, yöntemin durum değiştirmeyen (CSRF denetimi gerekmeyen) bir yöntem olup olmadığını döndürür.
+func SafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,58 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// İmzalama anahtarı; main, veritabanında saklanan anahtarı SetSigningKey ile yükler.
+// Yüklenmezse süreç ömrü boyunca geçerli rastgele bir anahtar kullanılır.
+var signingKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+func SetSigningKey(key []byte) {
+	signingKey = key
+}
+
+// Sign, değere HMAC-SHA256 imzası ekler: "<değer>.<imza>"
+func Sign(value string) string {
+	return value + "." + signature(value)
+}
+
+// Verify, Sign ile imzalanmış değeri doğrular ve imzasız değeri döndürür.
+func Verify(signed string) (string, bool) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", false
+	}
+	value, sig := signed[:i], signed[i+1:]
+	if !ValidSignature(value, sig) {
+		return "", false
+	}
+	return value, true
+}
+
+// Signature, değerin imzasını tek başına döndürür; imzalı URL gibi imzanın ayrı
+// taşındığı yerlerde kullanılır.
+func Signature(value string) string {
+	return signature(value)
+}
+
+// ValidSignature, imzayı sabit zamanlı karşılaştırmayla doğrular.
+func ValidSignature(value, sig string) bool {
+	return hmac.Equal([]byte(sig), []byte(signature(value)))
+}
+
+func signature(value string) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -1,209 +1,487 @@
-document.addEventListener('DOMContentLoaded', function () {
-  // Tema değiştirme düğmesi ve ikonu, sayfa ve body elementleri
-  const themeToggle = document.getElementById('themeToggle');
-  const themeIcon = document.getElementById('themeIcon');
-  const body = document.body;
-
-  // Temayı ayarlayan fonksiyon
-  const setTheme = (theme) => {
-    if (theme === 'night') {
-      body.classList.add('night-mode');
-      themeIcon.classList.replace('fa-sun', 'fa-moon');
-    } else if (theme === 'light') {
-      body.classList.remove('night-mode');
-      themeIcon.classList.replace('fa-moon', 'fa-sun');
-    }
+// Tüm sayfalarda ortak kullanılan betikler: tema, gönderi arama ve oylama
+
+// Kayıtlı temayı uygular ("light" veya "night"); kayıt yoksa tarayıcı tercihine bakılır
+(function () {
+  "use strict";
+  const prefersDark = window.matchMedia && window.matchMedia("(prefers-color-scheme: dark)").matches;
+  let tema = localStorage.getItem("tema") || (prefersDark ? "night" : "light");
+
+  const applyTheme = () => {
+    document.body.classList.remove("light-mode", "night-mode");
+    document.body.classList.add(tema + "-mode");
   };
+  applyTheme();
 
-  // Tema değiştirme düğmesine tıklama olayı
-  themeToggle.addEventListener('click', function () {
-    let currentTheme = 'day';
-    if (body.classList.contains('night-mode')) {
-      currentTheme = 'night';
-    } else if (body.style.getPropertyValue('--background-color') === 'rgb(255, 255, 255)') {
-      currentTheme = 'light';
-    }
-    // Tema durumuna göre temayı değiştir ve localStorage'a kaydet
-    if (currentTheme === 'day') {
-      setTheme('night');
-      localStorage.setItem('theme', 'night');
-    } else if (currentTheme === 'night') {
-      setTheme('light');
-      localStorage.setItem('theme', 'light');
-    }
+  // Tema değiştirme düğmesi
+  document.querySelectorAll("#themeToggle").forEach(toggle => {
+    toggle.addEventListener("click", event => {
+      event.preventDefault();
+      tema = tema === "night" ? "light" : "night";
+      localStorage.setItem("tema", tema);
+      applyTheme();
+    });
   });
-  // Kayıtlı temayı yükle veya varsayılan temayı kullan
-  const savedTheme = localStorage.getItem('theme') || 'day';
-  setTheme(savedTheme);
-});
-
-// Dil arama fonksiyonu
-function searchLanguages() {
-  // Arama kutusu, filtre ve sonuçları al
-  const input = document.getElementById('searchBox');
-  const filter = input.value.toLowerCase();
-  const nodes = document.getElementsByClassName('post');
-
-  // Arama sonuçlarını filtrele ve g ster
-  for (let i = 0; i < nodes.length; i++) {
-    if (nodes[i].innerText.toLowerCase().includes(filter)) {
-      nodes[i].style.display = "flex";
-    } else {
-      nodes[i].style.display = "none";
-    }
+})();
+
+// Üst çubuktaki arama kutusu sayfadaki gönderileri başlık, içerik ve kullanıcı adına göre süzer
+(function () {
+  "use strict";
+  const searchBox = document.querySelector("#bigbar #searchBox");
+  if (!searchBox) {
+    return;
   }
-}
 
-const selectedLanguages = [];
-const selectedLanguagesSet = new Set();
-const selectedLanguagesContainer = document.getElementById('selectedLanguagesContainer');
-
-// Dil arama fonksiyonu (filtreleme ve sonuçları g sterme)
-function searchLanguages() {
-  const searchTerm = searchBox.value.toLowerCase();
-  const filteredLanguages = languages.filter(lang =>
-    lang.toLowerCase().includes(searchTerm) && !selectedLanguages.includes(lang) // Seçilmemiş dilleri filtrele
-  );
-  // Sonuçları g ster
-  resultsContainer.innerHTML = '';
-  filteredLanguages.forEach(lang => {
-    const listItem = document.createElement('li');
-    listItem.textContent = lang;
-    listItem.onclick = () => selectLanguage(lang);
-    resultsContainer.appendChild(listItem);
+  searchBox.addEventListener("input", () => {
+    const filter = searchBox.value.toLowerCase();
+    document.querySelectorAll(".post").forEach(post => {
+      post.style.display = post.innerText.toLowerCase().includes(filter) ? "" : "none";
+    });
   });
+
+  // Enter tuşu ile sunucu tarafı arama
+  searchBox.addEventListener("keyup", event => {
+    if (event.key === "Enter") {
+      const url = new URL("/", window.location.origin);
+      url.searchParams.set("search", searchBox.value);
+      window.location.href = url.toString();
+    }
+  });
+})();
+
+// Çıkış yapmadan önce onay ister
+(function () {
+  "use strict";
+  const logoutButton = document.getElementById("logoutButton");
+  if (logoutButton) {
+    logoutButton.addEventListener("click", event => {
+      if (!confirm("Are you sure you want to log out?")) {
+        event.preventDefault();
+      }
+    });
+  }
+})();
+
+// Sayfadaki CSRF belirtecini döndürür (base düzenindeki meta etiketinden)
+function csrfToken() {
+  const meta = document.querySelector('meta[name="csrf-token"]');
+  return meta ? meta.content : "";
 }
 
-// Dil seçme fonksiyonu
-function selectLanguage(language) {
-  if (!selectedLanguagesSet.has(language) && selectedLanguages.length < 3) {
-    selectedLanguagesSet.add(language);
-    updateSelectedLanguages();
+// Gönderiye veya yoruma oy verir ve beğeni sayılarını günceller.
+// voteType: 1 beğeni, -1 beğenmeme
+function vote(postID, commentID, voteType) {
+  const body = new URLSearchParams({ vote_type: voteType });
+  if (postID) {
+    body.set("post_id", postID);
+  } else {
+    body.set("comment_id", commentID);
   }
+
+  fetch("/vote", {
+    method: "POST",
+    headers: {
+      "Accept": "application/json",
+      "X-CSRF-Token": csrfToken(),
+    },
+    body: body,
+  })
+    .then(response => response.json().then(data => ({ status: response.status, data: data })))
+    .then(({ status, data }) => {
+      if (status === 401 && data.redirect) {
+        window.location.href = data.redirect;
+        return;
+      }
+      if (status !== 200) {
+        alert(data.error || "Vote failed");
+        return;
+      }
+
+      const prefix = postID ? "post" : "comment";
+      const suffix = postID ? "" : "-" + commentID;
+      document.getElementById(prefix + "-like-count" + suffix).textContent = data.like_count;
+      document.getElementById(prefix + "-dislike-count" + suffix).textContent = data.dislike_count;
+    })
+    .catch(error => console.error("An error occurred:", error));
 }
 
-// Seçilen dilleri g ncelle
-function updateSelectedLanguages() {
-  selectedLanguagesList.innerHTML = ''; // Önceki seçilen dilleri temizle
+// Yer imleri: gönderi ve yorumlardaki "Save" düğmesi /api/bookmarks üzerinden kaydeder ya da
+// kaldırır. Canlı eklenen yorumlar için olay belgeye bağlanır.
+(function () {
+  "use strict";
+  const render = (button, id) => {
+    button.dataset.bookmark = id || "";
+    button.classList.toggle("saved", Boolean(id));
+    button.textContent = id ? "★ Saved" : "☆ Save";
+  };
 
-  // Seçilen dillerin listesini g ncelle
-  selectedLanguagesSet.forEach(lang => {
-    const listItem = document.createElement('li');
-    listItem.textContent = lang;
+  document.addEventListener("click", event => {
+    const button = event.target.closest(".bookmark-toggle");
+    if (!button) {
+      return;
+    }
+    const id = button.dataset.bookmark;
+    const headers = { "Accept": "application/json", "X-CSRF-Token": csrfToken() };
+    let request;
+    if (id) {
+      request = fetch("/api/bookmarks/" + id, { method: "DELETE", headers: headers });
+    } else {
+      const body = new URLSearchParams({ post_id: button.dataset.post });
+      if (button.dataset.comment) {
+        body.set("comment_id", button.dataset.comment);
+      }
+      request = fetch("/api/bookmarks", { method: "POST", headers: headers, body: body });
+    }
 
-    // Kategori silme işlevselliği ekleyin
-    listItem.onclick = () => {
-      selectedLanguagesSet.delete(lang);
-      updateSelectedLanguages();
-    };
+    button.disabled = true;
+    request
+      .then(response => {
+        // Başka bir sekmede kaldırılmış yer imi de kaldırılmış sayılır
+        if (response.status === 204 || (id && response.status === 404)) {
+          render(button, "");
+          return;
+        }
+        return response.json().then(data => {
+          if (!response.ok) {
+            alert(data.error || "Saving failed");
+            return;
+          }
+          render(button, data.bookmark.id);
+        });
+      })
+      .catch(error => console.error("Bookmark failed:", error))
+      .finally(() => { button.disabled = false; });
+  });
+})();
 
-    selectedLanguagesList.appendChild(listItem);
+// Flash mesajları: kapat düğmesi ve hata dışındaki mesajların birkaç saniye sonra kaybolması
+(function () {
+  "use strict";
+  const dismiss = flash => {
+    flash.style.opacity = "0";
+    setTimeout(() => flash.remove(), 400);
+  };
+
+  document.querySelectorAll("#flashes .flash").forEach(flash => {
+    flash.querySelector(".flash-close").addEventListener("click", () => dismiss(flash));
+    if (!flash.classList.contains("flash-error")) {
+      setTimeout(() => dismiss(flash), 5000);
+    }
   });
-}
+})();
 
-// Temizleme d ğmesine tıklama olayı
-clearButton.addEventListener('click', () => {
-  selectedLanguagesSet.clear(); // Set'i temizle
-  updateSelectedLanguages();
-  searchBox.value = ''; // Arama kutusunu temizle
-  searchLanguages(); // Arama sonuçlarını g ncelle
-});
+// Dosya ekleri: seçilen dosyalar listelenir, sürükleyerek sıralanır, her birine açıklama yazılabilir.
+// Form gönderildiğinde dosyalar listedeki sırayla "attachments", açıklamalar "captions" olarak gider.
+(function () {
+  "use strict";
+  const MB = 1024 * 1024;
+  // İçerik türlerinin boyut sınırı türü (sınırlar sunucudan data-max-* ile gelir)
+  const kinds = {
+    "image/jpeg": "image",
+    "image/png": "image",
+    "image/gif": "image",
+    "application/pdf": "pdf",
+    "text/plain": "text",
+  };
+  const extensionKinds = { jpg: "image", jpeg: "image", png: "image", gif: "image", pdf: "pdf", txt: "text" };
+
+  const kindOf = file => kinds[file.type] || extensionKinds[file.name.split(".").pop().toLowerCase()] || "";
+
+  document.querySelectorAll(".attachment-picker").forEach(picker => {
+    const input = picker.querySelector('input[type="file"]');
+    const list = picker.querySelector(".attachment-list");
+    const max = parseInt(picker.dataset.max, 10) || 10;
+    let items = []; // { file, caption }
+    let dragged = null;
+
+    // input.files'ı listedeki sıraya göre yeniden oluşturur
+    const sync = () => {
+      const transfer = new DataTransfer();
+      items.forEach(item => transfer.items.add(item.file));
+      input.files = transfer.files;
+    };
 
-// Dil arama kutusuna herhangi bir değişiklik yapıldığında aramayı başlatın
-searchBox.addEventListener('input', searchLanguages);
+    const render = () => {
+      list.innerHTML = "";
+      items.forEach((item, index) => {
+        const li = document.createElement("li");
+        li.className = "attachment-item";
+        li.draggable = true;
+        li.dataset.index = index;
 
-// Sayfa y klendiğinde seçilen dilleri g ncelle
-updateSelectedLanguages();
+        if (item.file.type.startsWith("image/")) {
+          const img = document.createElement("img");
+          img.src = URL.createObjectURL(item.file);
+          img.onload = () => URL.revokeObjectURL(img.src);
+          li.appendChild(img);
+        } else {
+          const icon = document.createElement("span");
+          icon.className = "attachment-icon";
+          icon.textContent = "📄";
+          li.appendChild(icon);
+        }
 
+        const name = document.createElement("span");
+        name.className = "attachment-name";
+        name.textContent = item.file.name;
+        li.appendChild(name);
 
-// Kullanıcı tercihlerine ve çerezlere dayalı olarak tema modunu belirler ve ayarlar
-(function (window, document, undefined) {
+        const caption = document.createElement("input");
+        caption.type = "text";
+        caption.name = "captions";
+        caption.maxLength = 200;
+        caption.placeholder = "Caption (optional)";
+        caption.value = item.caption;
+        caption.addEventListener("input", () => { item.caption = caption.value; });
+        li.appendChild(caption);
+
+        const remove = document.createElement("button");
+        remove.type = "button";
+        remove.className = "attachment-remove";
+        remove.textContent = "×";
+        remove.title = "Remove";
+        remove.addEventListener("click", () => {
+          items.splice(index, 1);
+          sync();
+          render();
+        });
+        li.appendChild(remove);
+
+        li.addEventListener("dragstart", event => {
+          dragged = index;
+          li.classList.add("dragging");
+          event.dataTransfer.effectAllowed = "move";
+        });
+        li.addEventListener("dragend", () => li.classList.remove("dragging"));
+        li.addEventListener("dragover", event => event.preventDefault());
+        li.addEventListener("drop", event => {
+          event.preventDefault();
+          if (dragged === null || dragged === index) {
+            return;
+          }
+          const [moved] = items.splice(dragged, 1);
+          items.splice(index, 0, moved);
+          dragged = null;
+          sync();
+          render();
+        });
+
+        list.appendChild(li);
+      });
+    };
+
+    input.addEventListener("change", () => {
+      const errors = [];
+      Array.from(input.files).forEach(file => {
+        const kind = kindOf(file);
+        const limit = parseInt(picker.dataset["max" + kind.charAt(0).toUpperCase() + kind.slice(1)], 10);
+        if (!kind) {
+          errors.push(file.name + ": only JPEG, PNG, GIF, PDF and text files are allowed.");
+        } else if (file.size > limit) {
+          errors.push(file.name + ": file size exceeds limit (" + Math.round(limit / MB * 10) / 10 + "MB).");
+        } else if (items.length >= max) {
+          errors.push(file.name + ": at most " + max + " files can be attached.");
+        } else {
+          items.push({ file: file, caption: "" });
+        }
+      });
+      if (errors.length > 0) {
+        alert(errors.join("\n"));
+      }
+      sync();
+      render();
+    });
+  });
+})();
+
+// Galeri görüntüleyici: küçük resme tıklanınca tam boyutu açar; oklar ve klavye ile gezinilir
+(function () {
   "use strict";
-  // Tarayıcıda kayıtlı "nightMode" çerezinin varlığını kontrol eder
-  var nightMode = document.cookie.indexOf("nightMode=true") !== -1;
-  // Tarayıcıda kayıtlı "nightMode" çerezinin olmadığını ve "lightMode" çerezinin varlığını kontrol eder
-  var lightMode = document.cookie.indexOf("nightMode=false") !== -1;
-  // Eğer "nightMode" çerezi varsa, body elementine "night-mode" sınıfını ekler
-  if (nightMode) {
-    document.body.classList.add("night-mode");
-  } else {
-    // Eğer "nightMode" çerezi yoksa, body elementine varsayılan olarak "light-mode" sınıfını ekler
-    document.body.classList.add("light-mode");
+  const lightbox = document.getElementById("lightbox");
+  if (!lightbox) {
+    return;
   }
+  const image = document.getElementById("lightboxImage");
+  const caption = document.getElementById("lightboxCaption");
+  let images = [];
+  let current = 0;
 
-  // Kullanıcının tarayıcı tercihlerine göre temayı belirler
-  const userPrefersDark = window.matchMedia && window.matchMedia("(prefers-color-scheme: dark)").matches;
-  const userPrefersLight = window.matchMedia && window.matchMedia("(prefers-color-scheme: light)").matches;
+  const show = index => {
+    current = (index + images.length) % images.length;
+    const img = images[current];
+    image.src = img.dataset.full;
+    image.alt = img.alt;
+    caption.textContent = img.dataset.caption || "";
+    lightbox.classList.toggle("single", images.length < 2);
+    lightbox.hidden = false;
+  };
+  const close = () => {
+    lightbox.hidden = true;
+    image.removeAttribute("src");
+  };
 
-  // Eğer tarayıcıda "nightMode" çerezi yok ve kullanıcı karanlık modu tercih ediyorsa, body elementine "night-mode" sınıfını ekler
-  if (!lightMode && userPrefersDark) {
-    document.body.classList.add("night-mode");
-  }
-  // Eğer tarayıcıda "nightMode" çerezi yok ve kullanıcı açık modu tercih ediyorsa, body elementine "light-mode" sınıfını ekler
-  if (!nightMode && userPrefersLight) {
-    document.body.classList.add("light-mode");
-  }
-})(window, document);
+  document.querySelectorAll(".gallery").forEach(gallery => {
+    const galleryImages = Array.from(gallery.querySelectorAll("img[data-full]"));
+    galleryImages.forEach((img, index) => {
+      img.addEventListener("click", () => {
+        images = galleryImages;
+        show(index);
+      });
+    });
+  });
 
-// Tema değiştirme işlevselliği ekler
-(function (window, document, undefined) {
-  "use strict";
-  // Tema değiştirme düğmesini ve ikonunu seçer
-  var nav = document.querySelector(".theme-mode");
-  // Tema değiştirme düğmesine bir gece modu düğmesi ekler
-  nav.innerHTML += '<span id="night-mode"><a role="button" title="nightMode" href="javascript:void(0);">🌓</a></span>';
-  var nightMode = document.querySelector("#night-mode");
-  // Gece modu düğmesine tıklama olayı ekler
-  nightMode.addEventListener("click", function (event) {
-    event.preventDefault();
-    // Body elementinden gece modu sınıfını kaldırır veya ekler
-    document.body.classList.toggle("light-mode");
-    document.body.classList.toggle("night-mode");
-    // Body'nin sınıflarına göre "nightMode" çerezini ayarlar
-    if (document.body.classList.contains("night-mode")) {
-      document.cookie = "nightMode=true; expires=Fri, 31 Dec 9999 23:59:59 GMT; path=/; secure;";
-    } else {
-      document.cookie = "nightMode=false; expires=Fri, 31 Dec 9999 23:59:59 GMT; path=/; secure;";
+  lightbox.querySelector(".lightbox-close").addEventListener("click", close);
+  lightbox.querySelector(".lightbox-prev").addEventListener("click", () => show(current - 1));
+  lightbox.querySelector(".lightbox-next").addEventListener("click", () => show(current + 1));
+  lightbox.addEventListener("click", event => {
+    if (event.target === lightbox) {
+      close();
+    }
+  });
+  document.addEventListener("keydown", event => {
+    if (lightbox.hidden) {
+      return;
+    }
+    if (event.key === "Escape") {
+      close();
+    } else if (event.key === "ArrowLeft") {
+      show(current - 1);
+    } else if (event.key === "ArrowRight") {
+      show(current + 1);
+    }
+  });
+})();
+
+// Renklendirilmiş kod bloklarına dil etiketi ve kopyalama düğmesi ekler. Önizleme gibi
+// sonradan eklenen içerik için tekrar çağrılabilir; satır numaraları kopyalanmaz.
+function enhanceCodeBlocks(root) {
+  (root || document).querySelectorAll(".code-block").forEach(block => {
+    if (block.querySelector(".code-copy")) {
+      return;
     }
-  }, false);
-})(window, document);
-
-// Sayfa yüklendiğinde tartışma başlığı, etiketler ve içerik için CKEditor'ı başlatır ve gönderme işlevselliği ekler
-document.addEventListener('DOMContentLoaded', function () {
-  // HTML'de tanımlı başlık, etiketler ve gönderme düğmesini seçer
-  const baslik = document.getElementById("baslik");
-  const etiketler = document.getElementById("etiketler");
-  const gonderButton = document.getElementById("gonder");
-
-  // CKEditor'ı başlatır
-  CKEDITOR.replace('editor');
-
-  // Gönderme düğmesine tıklama olayı ekler
-  gonderButton.addEventListener("click", () => {
-    const editorData = CKEDITOR.instances.editor.getData(); // CKEditor içeriğini alır
-    // Editor içeriğini sunucuya gönderir (örneğin: başlık, etiket, içerik)
-    alert("Tartışma başlatıldı! İçerik:\n" + editorData);
+    const lang = document.createElement("span");
+    lang.className = "code-lang";
+    lang.textContent = block.dataset.lang || "";
+    block.prepend(lang);
+
+    const button = document.createElement("button");
+    button.type = "button";
+    button.className = "code-copy";
+    button.textContent = "Copy";
+    button.addEventListener("click", () => {
+      const lines = block.querySelectorAll(".cl");
+      const text = lines.length
+        ? Array.from(lines, line => line.textContent).join("")
+        : block.querySelector("code").textContent;
+      navigator.clipboard.writeText(text).then(() => {
+        button.textContent = "Copied!";
+        setTimeout(() => { button.textContent = "Copy"; }, 1500);
+      }).catch(error => console.error("Copy failed:", error));
+    });
+    block.prepend(button);
   });
-});
+}
+enhanceCodeBlocks();
 
-// Belirli sınıfa sahip text bloklarının yatay kaydırma durumunu kontrol eder
-document.querySelectorAll('.text-block').forEach(block => {
-  if (block.scrollWidth > block.clientWidth) {
-    block.style.overflow = 'auto';
+// Canlı güncellemeler (Server-Sent Events): gönderi sayfasında yeni yorumlar ve oy sayıları,
+// ana sayfada yeni gönderi duyurusu ve üst çubuktaki okunmamış bildirim sayısı
+(function () {
+  "use strict";
+  const livePost = document.querySelector("[data-live-post]");
+  const feedBanner = document.getElementById("newPostsBanner");
+  const bell = document.getElementById("notificationBell");
+  if (!window.EventSource || (!livePost && !feedBanner && !bell)) {
+    return;
   }
-});
 
-// Karakter sayacı ekler
-var textarea = document.getElementById("content");
-var charCount = document.getElementById("charCount");
+  const url = new URL("/events", window.location.origin);
+  if (livePost) {
+    url.searchParams.set("post", livePost.dataset.livePost);
+  }
+  const source = new EventSource(url);
 
-textarea.addEventListener("input", function () {
-  var charLength = textarea.value.length;
-  charCount.textContent = "Characters: " + charLength + "/600";
+  source.addEventListener("vote", event => {
+    const data = JSON.parse(event.data);
+    const prefix = data.comment_id ? "comment" : "post";
+    const suffix = data.comment_id ? "-" + data.comment_id : "";
+    const likes = document.getElementById(prefix + "-like-count" + suffix);
+    const dislikes = document.getElementById(prefix + "-dislike-count" + suffix);
+    if (likes && dislikes) {
+      likes.textContent = data.like_count;
+      dislikes.textContent = data.dislike_count;
+    }
+  });
 
-  if (charLength > 600) {
-    charCount.style.color = "red";
-  } else {
-    charCount.style.color = "black";
+  // Yeni yorum, okuyucunun yetkilerine göre (ör. silme düğmesi) sunucuda işlenmiş parçasıyla
+  // eklenir. Sayfadaki sırayla aynı olsun diye en üste, kabul edilmiş cevap varsa onun altına konur.
+  source.addEventListener("comment", event => {
+    const data = JSON.parse(event.data);
+    const comments = document.getElementById("comments");
+    if (!comments || document.getElementById("comment-" + data.id)) {
+      return;
+    }
+    fetch("/comments/" + encodeURIComponent(data.id))
+      .then(response => {
+        if (!response.ok) {
+          throw new Error(response.status + " " + response.statusText);
+        }
+        return response.text();
+      })
+      .then(html => {
+        if (document.getElementById("comment-" + data.id)) {
+          return;
+        }
+        const fragment = document.createElement("template");
+        fragment.innerHTML = html;
+        const anchor = fragment.content.querySelector(".comment-anchor");
+        const card = anchor && anchor.nextElementSibling;
+        if (!card) {
+          return;
+        }
+        card.classList.add("live-new");
+        const accepted = comments.querySelector(":scope > .accepted");
+        if (accepted) {
+          accepted.after(anchor, card);
+        } else {
+          comments.prepend(anchor, card);
+        }
+        enhanceCodeBlocks(card);
+      })
+      .catch(error => console.error("Loading new comment failed:", error));
+  });
+
+  let newPosts = 0;
+  source.addEventListener("post", () => {
+    if (!feedBanner) {
+      return;
+    }
+    newPosts++;
+    feedBanner.textContent = newPosts === 1 ? "1 new post" : newPosts + " new posts";
+    feedBanner.hidden = false;
+  });
+  if (feedBanner) {
+    feedBanner.addEventListener("click", event => {
+      event.preventDefault();
+      window.location.reload();
+    });
   }
-});
\ No newline at end of file
+
+  source.addEventListener("notifications", event => {
+    if (!bell) {
+      return;
+    }
+    const unread = JSON.parse(event.data).unread;
+    let count = bell.querySelector(".bell-count");
+    if (unread === 0) {
+      if (count) {
+        count.remove();
+      }
+      return;
+    }
+    if (!count) {
+      count = document.createElement("span");
+      count.className = "bell-count";
+      bell.append(count);
+    }
+    count.textContent = unread > 99 ? "99+" : unread;
+  });
+})();
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"form-project/security"
+	"form-project/utils"
+)
+
+// /uploads/ altındaki imzalı adresler: /uploads/<anahtar>?expires=<unix>&sig=<imza>
+func signedPath(key string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	q := url.Values{"expires": {exp}, "sig": {security.Signature(signedValue(key, exp))}}
+	return "/uploads/" + key + "?" + q.Encode()
+}
+
+func signedValue(key, expires string) string {
+	return "upload:" + key + ":" + expires
+}
+
+// İsteğin süresi dolmamış geçerli bir imza taşıyıp taşımadığını kontrol eder.
+func validSignature(r *http.Request, key string) bool {
+	exp, sig := r.URL.Query().Get("expires"), r.URL.Query().Get("sig")
+	if exp == "" || sig == "" {
+		return false
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return security.ValidSignature(signedValue(key, exp), sig)
+}
+
+// Handler, /uploads/ altındaki nesneleri depodan sunar. Dizin listelemesi yapılmaz; gizli
+// anahtarlar bulunamadı olarak döner, PrivatePrefix altındakiler imzalı adres gerektirir.
+// S3 gibi uzak depolarda nesne uygulama üzerinden aktarılır (public_url tanımlı değilse).
+func Handler(store BlobStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			utils.WriteError(w, r, utils.MethodNotAllowed())
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, "/uploads/")
+		if ValidKey(key) != nil || hidden(key) {
+			utils.WriteError(w, r, utils.NotFound("File not found"))
+			return
+		}
+		private := strings.HasPrefix(key, PrivatePrefix)
+		if private && !validSignature(r, key) {
+			utils.WriteError(w, r, utils.Forbidden("This link is invalid or has expired"))
+			return
+		}
+
+		obj, err := store.Get(r.Context(), key)
+		if errors.Is(err, ErrNotFound) {
+			utils.WriteError(w, r, utils.NotFound("File not found"))
+			return
+		}
+		if err != nil {
+			utils.WriteError(w, r, utils.Internal(fmt.Errorf("get %s from %s store: %w", key, store.Name(), err)))
+			return
+		}
+		defer obj.Body.Close()
+
+		w.Header().Set("Content-Type", obj.ContentType)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		// Görsel dışındaki dosyalar (PDF, metin) sitenin kaynağında açılmak yerine indirilir
+		if !strings.HasPrefix(obj.ContentType, "image/") {
+			w.Header().Set("Content-Disposition", "attachment")
+		}
+		if private {
+			w.Header().Set("Cache-Control", "private, no-store")
+		} else {
+			// Anahtarlar UUID'dir ve içerikleri değişmez
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		}
+
+		if rs, ok := obj.Body.(io.ReadSeeker); ok {
+			http.ServeContent(w, r, key, obj.ModTime, rs)
+			return
+		}
+		if !obj.ModTime.IsZero() {
+			w.Header().Set("Last-Modified", obj.ModTime.UTC().Format(http.TimeFormat))
+		}
+		if obj.Size > 0 {
+			w.Header().Set("Content-Length", strconv.FormatInt(obj.Size, 10))
+		}
+		if r.Method == http.MethodHead {
+			return
+		}
+		io.Copy(w, obj.Body)
+	})
+}
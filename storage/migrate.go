@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MigrateReport, Migrate çalışmasının özetidir.
+type MigrateReport struct {
+	Scanned int      // Kaynaktaki nesne sayısı
+	Copied  int      // Kopyalanan (dry-run'da kopyalanacak) nesne sayısı
+	Skipped int      // Hedefte aynı boyutta zaten bulunan nesneler
+	Deleted int      // Kopyalandıktan sonra kaynaktan silinen nesneler
+	Failed  []string // Taşınamayan nesneler ve nedenleri
+}
+
+// Migrate, kaynaktaki tüm nesneleri hedefe kopyalar. Hedefte aynı boyutta bulunan nesneler
+// atlanır, böylece komut yarıda kalırsa güvenle yeniden çalıştırılabilir. deleteSource true
+// ise başarıyla kopyalanan (veya zaten hedefte olan) nesneler kaynaktan silinir.
+func Migrate(ctx context.Context, src, dst BlobStore, dryRun, deleteSource bool) (MigrateReport, error) {
+	var report MigrateReport
+
+	keys, err := src.List(ctx, "")
+	if err != nil {
+		return report, fmt.Errorf("list %s store: %w", src.Name(), err)
+	}
+
+	for _, key := range keys {
+		report.Scanned++
+
+		srcInfo, err := src.Stat(ctx, key)
+		if err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		dstInfo, err := dst.Stat(ctx, key)
+		switch {
+		case err == nil && dstInfo.Size == srcInfo.Size:
+			report.Skipped++
+		case err != nil && !errors.Is(err, ErrNotFound):
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", key, err))
+			continue
+		case dryRun:
+			report.Copied++
+			continue
+		default:
+			if err := copyBlob(ctx, src, dst, key); err != nil {
+				report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", key, err))
+				continue
+			}
+			report.Copied++
+		}
+
+		if deleteSource && !dryRun {
+			if err := src.Delete(ctx, key); err != nil {
+				report.Failed = append(report.Failed, fmt.Sprintf("%s: delete from %s: %v", key, src.Name(), err))
+				continue
+			}
+			report.Deleted++
+		}
+	}
+	return report, nil
+}
+
+func copyBlob(ctx context.Context, src, dst BlobStore, key string) error {
+	obj, err := src.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer obj.Body.Close()
+
+	data, err := io.ReadAll(obj.Body)
+	if err != nil {
+		return err
+	}
+	return dst.Put(ctx, key, data, obj.ContentType)
+}
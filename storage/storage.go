@@ -0,0 +1,103 @@
+package storage // Yüklenen dosyaları yerel diskte veya S3 uyumlu bir nesne deposunda saklayan paket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+
+	"form-project/config"
+)
+
+// PrivatePrefix altındaki anahtarlar yalnızca imzalı URL ile sunulur.
+const PrivatePrefix = "private/"
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore, yüklenen dosyaların saklandığı arka uçtur. Anahtarlar "/" ile ayrılmış
+// göreli yollardır (ör. "uuid.jpg", "private/uuid.png").
+type BlobStore interface {
+	// Name, arka ucun adıdır ("local", "s3"); günlük ve komut çıktılarında kullanılır.
+	Name() string
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get, nesneyi okumak için açar; yoksa ErrNotFound döner. Body kapatılmalıdır.
+	Get(ctx context.Context, key string) (*Object, error)
+	// Stat, nesnenin bilgilerini döndürür; yoksa ErrNotFound döner.
+	Stat(ctx context.Context, key string) (*Info, error)
+	// Delete, nesneyi siler; nesne zaten yoksa hata döndürmez.
+	Delete(ctx context.Context, key string) error
+	// List, önekle başlayan anahtarları döndürür. Gizli (nokta ile başlayan) anahtarlar atlanır.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// URL, herkese açık bir nesnenin kalıcı adresidir.
+	URL(key string) string
+	// SignedURL, verilen süre boyunca geçerli, imzalı bir adres üretir (özel içerik için).
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+// Info, bir nesnenin meta verileridir.
+type Info struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Object, okunmak üzere açılmış bir nesnedir.
+type Object struct {
+	Info
+	Body io.ReadCloser
+}
+
+// New, yapılandırmadaki arka ucu oluşturur. backend boş değilse yapılandırmadaki seçimi ezer
+// (ör. migrate-uploads komutunda kaynak ve hedef için).
+func New(cfg config.Storage, backend string) (BlobStore, error) {
+	if backend == "" {
+		backend = cfg.Backend
+	}
+	switch backend {
+	case "local":
+		return NewLocal(cfg.LocalDir), nil
+	case "s3":
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (expected local or s3)", backend)
+	}
+}
+
+// ValidKey, anahtarın depo dışına çıkmayan, temiz bir göreli yol olduğunu kontrol eder.
+func ValidKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." || part == "." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// Anahtarın herhangi bir parçası nokta ile başlıyorsa gizlidir (geçici dosyalar, sağlık kontrolü).
+func hidden(key string) bool {
+	for _, part := range strings.Split(key, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Uzantıdan içerik türünü tahmin eder; bilinmiyorsa application/octet-stream döner.
+func contentTypeOf(key string) string {
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
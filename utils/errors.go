@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+)
+
+// AppError, bir HTTP durum koduna eşlenen tipli uygulama hatasıdır.
+// Message kullanıcıya gösterilir, Err ise yalnızca loglanır.
+type AppError struct {
+	Status  int
+	Message string
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+func NewError(status int, message string, err error) *AppError {
+	return &AppError{Status: status, Message: message, Err: err}
+}
+
+func BadRequest(message string, err error) *AppError {
+	return NewError(http.StatusBadRequest, message, err)
+}
+
+func Unauthorized(message string) *AppError {
+	return NewError(http.StatusUnauthorized, message, nil)
+}
+
+func Forbidden(message string) *AppError {
+	return NewError(http.StatusForbidden, message, nil)
+}
+
+func NotFound(message string) *AppError {
+	return NewError(http.StatusNotFound, message, nil)
+}
+
+func MethodNotAllowed() *AppError {
+	return NewError(http.StatusMethodNotAllowed, "Method not allowed", nil)
+}
+
+func TooManyRequests(message string) *AppError {
+	return NewError(http.StatusTooManyRequests, message, nil)
+}
+
+func Internal(err error) *AppError {
+	return NewError(http.StatusInternalServerError, "Internal server error", err)
+}
+
+// Hatanın HTTP durum kodunu döndürür: AppError kendi kodunu taşır,
+// sql.ErrNoRows 404'e, diğer her şey 500'e eşlenir.
+func StatusFor(err error) int {
+	var appErr *AppError
+	switch {
+	case errors.As(err, &appErr):
+		return appErr.Status
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Kullanıcıya gösterilecek mesajı döndürür; iç hata ayrıntıları asla dışarı sızdırılmaz.
+func MessageFor(err error) string {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return "Not found"
+	}
+	return "Internal server error"
+}
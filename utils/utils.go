@@ -2,16 +2,84 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
+
+	"form-project/render"
 )
 
-func HandleErr(w http.ResponseWriter, err error, message string, statusCode int) {
-    log.Println(err)
-    w.WriteHeader(statusCode)
-    w.Header().Set("Content-Type", "application/json")
+// HandleErr, hatayı loglar ve isteği yapan tarafa uygun biçimde yanıt verir:
+// tarayıcılara stilli bir HTML hata sayfası, API/fetch isteklerine JSON zarfı.
+func HandleErr(w http.ResponseWriter, r *http.Request, err error, message string, statusCode int) {
+	if err != nil || statusCode >= http.StatusInternalServerError {
+		log.Printf("%s %s: %d %s: %v", r.Method, r.URL.Path, statusCode, message, err)
+	}
+
+	if WantsJSON(r) {
+		writeJSONError(w, message, statusCode)
+		return
+	}
+	renderErrorPage(w, r, message, statusCode)
+}
+
+// WriteError, tipli uygulama hatalarından durum kodunu ve kullanıcıya gösterilecek mesajı çıkarır.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	// Yalnızca altta yatan neden loglanır; kullanıcı hatası (ör. 404) gürültü yaratmaz
+	cause := err
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		cause = appErr.Err
+	}
+	HandleErr(w, r, cause, MessageFor(err), StatusFor(err))
+}
+
+// İsteğin HTML yerine JSON yanıt beklediğini tespit eder.
+func WantsJSON(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		return true
+	}
+	if r.Header.Get("X-Requested-With") == "XMLHttpRequest" {
+		return true
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+func writeJSONError(w http.ResponseWriter, message string, statusCode int) {
+	// Başlıklar WriteHeader'dan önce ayarlanmalı, aksi halde yok sayılır
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(statusCode)
+
+	response := map[string]interface{}{"error": message, "status": statusCode}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Hata sayfası şablonuna aktarılan veriler
+type errorPageData struct {
+	render.Page
+	Status  int
+	Title   string
+	Message string
+}
+
+func renderErrorPage(w http.ResponseWriter, r *http.Request, message string, statusCode int) {
+	data := errorPageData{
+		Page:    render.NewPage(w, r),
+		Status:  statusCode,
+		Title:   http.StatusText(statusCode),
+		Message: message,
+	}
 
-    // Hata mesajını JSON olarak gönder
-    response := map[string]string{"error": message}
-    json.NewEncoder(w).Encode(response)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if err := render.HTML(w, statusCode, "error", data); err != nil {
+		// Şablon işlenemezse düz metin yanıta geri dönülür
+		log.Println("Error rendering error template:", err)
+		http.Error(w, message, statusCode)
+	}
 }